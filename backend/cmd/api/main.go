@@ -5,25 +5,41 @@ import (
 	"fmt"
 	"log"
 
+	"backend/internal/core/audit"
 	"backend/internal/core/config"
 	"backend/internal/core/db"
+	"backend/internal/core/mailer"
+	"backend/internal/core/rbac"
 	"backend/internal/core/server"
+	"backend/internal/core/server/middleware"
+	coreUtils "backend/internal/core/utils"
 
 	// Domains for Migration
+	auditDomain "backend/internal/modules/audit/domain"
 	diaryDomain "backend/internal/modules/diary/domain"
 	projectDomain "backend/internal/modules/project/domain"
 	resumeDomain "backend/internal/modules/resume/domain"
+	revisionDomain "backend/internal/modules/revision/domain"
 	socialDomain "backend/internal/modules/social/domain"
 	systemDomain "backend/internal/modules/system/domain"
 	authDomain "backend/internal/modules/user/domain"
 
+	// Repositories for data migrations
+	auditRepository "backend/internal/modules/audit/repository"
+	revisionRepository "backend/internal/modules/revision/repository"
+	searchRepository "backend/internal/modules/search/repository"
+	userRepository "backend/internal/modules/user/repository"
+
 	// Handlers
+	auditHandler "backend/internal/modules/audit/handler"
 	authHandler "backend/internal/modules/auth/handler"
 	diaryHandler "backend/internal/modules/diary/handler"
 	projectHandler "backend/internal/modules/project/handler"
 	resumeHandler "backend/internal/modules/resume/handler"
+	searchHandler "backend/internal/modules/search/handler"
 	socialHandler "backend/internal/modules/social/handler"
 	systemHandler "backend/internal/modules/system/handler"
+	userHandler "backend/internal/modules/user/handler"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/common/utils"
@@ -33,25 +49,57 @@ import (
 func main() {
 	// 1. Load Env
 	config.Init()
+	coreUtils.InitJWT()
 
-	// 2. Init DB
+	// 2. Init DB & Mailer
 	db.Init()
+	mailer.Init()
 
 	// 3. Auto Migrate
 	// Note: Ideally move to a separate migration tool/command
 	err := db.DB.AutoMigrate(
 		&authDomain.User{},
+		&authDomain.RefreshToken{},
+		&authDomain.Invite{},
+		&authDomain.LoginAttempt{},
+		&authDomain.APIToken{},
+		&authDomain.Identity{},
 		&projectDomain.Project{},
+		&diaryDomain.Tag{},
 		&diaryDomain.DiaryEntry{},
 		&resumeDomain.Experience{},
 		&resumeDomain.Skill{},
 		&socialDomain.SocialLinkGorm{},
 		&systemDomain.SystemConfig{},
+		&auditDomain.Entry{},
+		&revisionDomain.Revision{},
 	)
 	if err != nil {
 		log.Fatalf("failed to migrate database: %v", err)
 	}
 
+	// Usernames and emails of deleted users used to stay reserved
+	if err := userRepository.NewPostgresUserRepository().DropLegacyUniqueIndexes(context.Background()); err != nil {
+		log.Fatalf("failed to drop legacy user indexes: %v", err)
+	}
+
+	// Installs created before roles existed have no owner yet
+	if err := userRepository.NewPostgresUserRepository().EnsureOwner(context.Background()); err != nil {
+		log.Fatalf("failed to assign owner role: %v", err)
+	}
+
+	if err := auditRepository.NewPostgresAuditRepository().EnsureAppendOnly(context.Background()); err != nil {
+		log.Fatalf("failed to protect audit log: %v", err)
+	}
+
+	if err := revisionRepository.NewPostgresRevisionRepository().EnsureImmutable(context.Background()); err != nil {
+		log.Fatalf("failed to protect revisions: %v", err)
+	}
+
+	if err := searchRepository.NewPostgresSearchRepository().EnsureSearchIndex(context.Background()); err != nil {
+		log.Fatalf("failed to create search index: %v", err)
+	}
+
 	// 4. Init Hertz Server
 	h := server.NewServer()
 
@@ -59,12 +107,36 @@ func main() {
 	authH := authHandler.NewAuthHandler()
 	projectH := projectHandler.NewProjectHandler()
 	diaryH := diaryHandler.NewDiaryHandler()
+	tagH := diaryHandler.NewTagHandler()
 	resumeExpH := resumeHandler.NewExperienceHandler()
 	resumeSkillH := resumeHandler.NewSkillHandler()
 	socialH := socialHandler.NewSocialLinkHandler()
 	systemH := systemHandler.NewSystemHandler()
+	userH := userHandler.NewUserHandler()
+	auditH := auditHandler.NewAuditHandler()
+	searchH := searchHandler.NewSearchHandler()
+
+	// Accept personal access tokens wherever JWTs are accepted
+	middleware.UseAPITokens(authH.APITokenValidator())
+
+	// Services record security-relevant events through the audit package
+	audit.Use(auditH.Recorder())
+
+	// Publish scheduled diary entries in the background
+	go diaryH.RunScheduler(context.Background())
+
+	if config.SetupRequireToken {
+		token, err := systemH.IssueSetupToken(context.Background())
+		if err != nil {
+			log.Fatalf("failed to issue setup token: %v", err)
+		}
+		if token != "" {
+			log.Printf("System is not set up yet. Setup token: %s", token)
+		}
+	}
 
 	// 6. Register Routes
+	h.GET("/.well-known/jwks.json", authH.JWKS)
 	h.GET("/ping", func(c context.Context, ctx *app.RequestContext) {
 		ctx.JSON(consts.StatusOK, utils.H{"message": "pong"})
 	})
@@ -75,51 +147,121 @@ func main() {
 		sys := api.Group("/system")
 		sys.GET("/status", systemH.GetStatus)
 		sys.POST("/setup", systemH.Setup)
+		sys.PUT("/registration", middleware.JWTAuth(), middleware.RequirePermission(rbac.PermManageSystem), systemH.SetRegistrationMode)
+		sys.GET("/settings", systemH.GetSettings)
+		sys.PUT("/settings", middleware.JWTAuth(), middleware.RequirePermission(rbac.PermManageSystem), systemH.UpdateSettings)
 
 		// Auth
 		auth := api.Group("/auth")
 		auth.POST("/register", authH.Register)
 		auth.POST("/login", authH.Login)
+		auth.POST("/refresh", authH.Refresh)
+		auth.POST("/logout", authH.Logout)
+		auth.POST("/mfa/verify", authH.VerifyMFA)
+		auth.POST("/password/forgot", authH.ForgotPassword)
+		auth.POST("/password/reset", authH.ResetPassword)
+		auth.POST("/password/change", middleware.JWTAuth(), middleware.SessionOnly(), authH.ChangePassword)
+		auth.GET("/oidc/login", authH.OIDCLogin)
+		auth.GET("/oidc/callback", authH.OIDCCallback)
+
+		totp := auth.Group("/mfa/totp", middleware.JWTAuth(), middleware.SessionOnly())
+		{
+			totp.POST("/setup", authH.SetupTOTP)
+			totp.POST("/confirm", authH.ConfirmTOTP)
+			totp.POST("/disable", authH.DisableTOTP)
+		}
+
+		tokens := auth.Group("/tokens", middleware.JWTAuth(), middleware.SessionOnly())
+		{
+			tokens.GET("/", authH.GetAPITokens)
+			tokens.POST("/", authH.CreateAPIToken)
+			tokens.DELETE("/:id", authH.RevokeAPIToken)
+		}
+
+		invites := auth.Group("/invites", middleware.JWTAuth(), middleware.RequirePermission(rbac.PermManageUsers))
+		{
+			invites.GET("/", authH.GetInvites)
+			invites.POST("/", authH.CreateInvite)
+			invites.DELETE("/:id", authH.DeleteInvite)
+		}
+
+		// Users
+		api.GET("/me", middleware.JWTAuth(), userH.GetMe)
+		api.PUT("/me", middleware.JWTAuth(), middleware.SessionOnly(), userH.UpdateMe)
+
+		users := api.Group("/users", middleware.JWTAuth(), middleware.RequirePermission(rbac.PermManageUsers))
+		{
+			users.GET("/", userH.GetUsers)
+			users.GET("/:id", userH.GetUser)
+			users.POST("/", userH.CreateUser)
+			users.PUT("/:id", userH.UpdateUser)
+			users.POST("/:id/deactivate", userH.DeactivateUser)
+			users.POST("/:id/activate", userH.ActivateUser)
+			users.DELETE("/:id", userH.DeleteUser)
+		}
+
+		// Audit log
+		api.GET("/audit", middleware.JWTAuth(), middleware.RequirePermission(rbac.PermAuditRead), auditH.GetEntries)
 
 		// Public APIs
 		api.GET("/projects", projectH.GetProjects)
 		api.GET("/projects/:slug", projectH.GetProject)
-		api.GET("/diaries", diaryH.GetDiaries)
-		api.GET("/diaries/:slug", diaryH.GetDiary)
+		api.GET("/diaries", middleware.OptionalAuth(), diaryH.GetDiaries)
+		api.GET("/diaries/:slug", middleware.OptionalAuth(), diaryH.GetDiary)
+		api.GET("/tags", middleware.OptionalAuth(), tagH.GetTags)
 		api.GET("/skills", resumeSkillH.GetSkills)
 		api.GET("/experiences", resumeExpH.GetExperiences)
 		api.GET("/social-links", socialH.GetSocialLinks)
+		api.GET("/search", middleware.OptionalAuth(), searchH.Search)
 
-		// Protected APIs (TODO: Add Middleware)
-		projects := api.Group("/projects")
+		// Protected APIs
+		projects := api.Group("/projects", middleware.JWTAuth())
 		{
-			projects.POST("/", projectH.CreateProject)
-			projects.PUT("/:id", projectH.UpdateProject)
-			projects.DELETE("/:id", projectH.DeleteProject)
+			projects.POST("/", middleware.RequirePermission(rbac.PermProjectWrite), projectH.CreateProject)
+			projects.PUT("/:id", middleware.RequirePermission(rbac.PermProjectWrite), projectH.UpdateProject)
+			projects.DELETE("/:id", middleware.RequirePermission(rbac.PermProjectDelete), projectH.DeleteProject)
+
+			// Revisions can hold unpublished content, so reading them needs write access too
+			projects.GET("/:id/revisions", middleware.RequirePermission(rbac.PermProjectWrite), projectH.GetRevisions)
+			projects.GET("/:id/revisions/diff", middleware.RequirePermission(rbac.PermProjectWrite), projectH.DiffRevisions)
+			projects.GET("/:id/revisions/:version", middleware.RequirePermission(rbac.PermProjectWrite), projectH.GetRevision)
+			projects.POST("/:id/revisions/:version/restore", middleware.RequirePermission(rbac.PermProjectWrite), projectH.RestoreRevision)
+		}
+
+		diaries := api.Group("/diaries", middleware.JWTAuth())
+		{
+			diaries.POST("/", middleware.RequirePermission(rbac.PermDiaryWrite), diaryH.CreateDiary)
+			diaries.PUT("/:id", middleware.RequirePermission(rbac.PermDiaryWrite), diaryH.UpdateDiary)
+			diaries.DELETE("/:id", middleware.RequirePermission(rbac.PermDiaryDelete), diaryH.DeleteDiary)
+
+			diaries.GET("/:id/revisions", middleware.RequirePermission(rbac.PermDiaryWrite), diaryH.GetRevisions)
+			diaries.GET("/:id/revisions/diff", middleware.RequirePermission(rbac.PermDiaryWrite), diaryH.DiffRevisions)
+			diaries.GET("/:id/revisions/:version", middleware.RequirePermission(rbac.PermDiaryWrite), diaryH.GetRevision)
+			diaries.POST("/:id/revisions/:version/restore", middleware.RequirePermission(rbac.PermDiaryWrite), diaryH.RestoreRevision)
 		}
 
-		diaries := api.Group("/diaries")
+		tags := api.Group("/tags", middleware.JWTAuth())
 		{
-			diaries.POST("/", diaryH.CreateDiary)
-			diaries.PUT("/:id", diaryH.UpdateDiary)
-			diaries.DELETE("/:id", diaryH.DeleteDiary)
+			tags.POST("/", middleware.RequirePermission(rbac.PermDiaryWrite), tagH.CreateTag)
+			tags.PUT("/:id", middleware.RequirePermission(rbac.PermDiaryWrite), tagH.UpdateTag)
+			tags.DELETE("/:id", middleware.RequirePermission(rbac.PermDiaryDelete), tagH.DeleteTag)
 		}
 
-		skills := api.Group("/skills")
+		skills := api.Group("/skills", middleware.JWTAuth(), middleware.RequirePermission(rbac.PermResumeWrite))
 		{
 			skills.POST("/", resumeSkillH.CreateSkill)
 			skills.PUT("/:id", resumeSkillH.UpdateSkill)
 			skills.DELETE("/:id", resumeSkillH.DeleteSkill)
 		}
 
-		experiences := api.Group("/experiences")
+		experiences := api.Group("/experiences", middleware.JWTAuth(), middleware.RequirePermission(rbac.PermResumeWrite))
 		{
 			experiences.POST("/", resumeExpH.CreateExperience)
 			experiences.PUT("/:id", resumeExpH.UpdateExperience)
 			experiences.DELETE("/:id", resumeExpH.DeleteExperience)
 		}
 
-		socialLinks := api.Group("/social-links")
+		socialLinks := api.Group("/social-links", middleware.JWTAuth(), middleware.RequirePermission(rbac.PermSocialWrite))
 		{
 			socialLinks.POST("/", socialH.CreateSocialLink)
 			socialLinks.PUT("/:id", socialH.UpdateSocialLink)
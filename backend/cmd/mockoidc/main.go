@@ -0,0 +1,36 @@
+// Command mockoidc runs a local OpenID Connect provider for trying out single sign-on
+// without registering an application anywhere. Point the API at it with:
+//
+//	OIDC_PROVIDER=oidc
+//	OIDC_ISSUER=http://localhost:9999
+//	OIDC_CLIENT_ID=portfolio-cms
+//	OIDC_CLIENT_SECRET=mock-secret
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"backend/internal/core/oidc/mockprovider"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:9999", "listen address")
+	issuer := flag.String("issuer", "http://localhost:9999", "public base URL of the provider")
+	clientID := flag.String("client-id", "portfolio-cms", "accepted client ID")
+	clientSecret := flag.String("client-secret", "mock-secret", "accepted client secret")
+	flag.Parse()
+
+	provider, err := mockprovider.New(mockprovider.Config{
+		Issuer:       *issuer,
+		ClientID:     *clientID,
+		ClientSecret: *clientSecret,
+	})
+	if err != nil {
+		log.Fatalf("failed to start mock provider: %v", err)
+	}
+
+	log.Printf("Mock OIDC provider running on %s", *issuer)
+	log.Fatal(http.ListenAndServe(*addr, provider.Handler()))
+}
@@ -0,0 +1,89 @@
+// Package audit records security-relevant events. Services call Record with what
+// happened; who did it and from where is taken from the request context, which the
+// HTTP middleware fills in.
+package audit
+
+import (
+	"context"
+	"log"
+	"strconv"
+)
+
+// Event is a single audited action. Target identifies the affected record, e.g.
+// TargetType "diary" and TargetID "12".
+type Event struct {
+	ActorID    *uint // Defaults to the authenticated user of the request
+	Action     string
+	TargetType string
+	TargetID   string
+	Changes    map[string]Change
+}
+
+// Recorder stores events, implemented by the audit module.
+type Recorder interface {
+	Record(ctx context.Context, event Event, client Client) error
+}
+
+var recorder Recorder
+
+// Use sets the recorder events are written to. Until it is called events are dropped.
+func Use(r Recorder) {
+	recorder = r
+}
+
+// Record writes an event. Failures are logged rather than returned, the audited action
+// has already happened by the time it is recorded.
+func Record(ctx context.Context, event Event) {
+	if recorder == nil {
+		return
+	}
+	if event.ActorID == nil {
+		event.ActorID = actorFrom(ctx)
+	}
+	if err := recorder.Record(ctx, event, clientFrom(ctx)); err != nil {
+		log.Printf("audit: failed to record %s: %v", event.Action, err)
+	}
+}
+
+// Client describes where the request came from.
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+type contextKey int
+
+const (
+	actorKey contextKey = iota
+	clientKey
+)
+
+func WithActor(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, actorKey, userID)
+}
+
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey, client)
+}
+
+// Actor returns the user set by WithActor, or nil for anonymous and background work.
+func Actor(ctx context.Context) *uint {
+	return actorFrom(ctx)
+}
+
+func actorFrom(ctx context.Context) *uint {
+	if userID, ok := ctx.Value(actorKey).(uint); ok {
+		return &userID
+	}
+	return nil
+}
+
+func clientFrom(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey).(Client)
+	return client
+}
+
+// ID formats a numeric primary key as a TargetID.
+func ID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
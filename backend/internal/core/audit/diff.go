@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Change is the before and after value of a field. Before is nil for created records,
+// After is nil for deleted ones.
+type Change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Fields that change on every write and would only add noise
+var ignoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+// Diff compares the JSON form of two values and returns the fields that differ. Either
+// side may be nil. Fields hidden from JSON, like password hashes, never show up.
+func Diff(before, after interface{}) map[string]Change {
+	b := toMap(before)
+	a := toMap(after)
+
+	changes := map[string]Change{}
+	for key, value := range b {
+		if !ignoredFields[key] && !reflect.DeepEqual(value, a[key]) {
+			changes[key] = Change{Before: value, After: a[key]}
+		}
+	}
+	for key, value := range a {
+		if _, seen := b[key]; !seen && value != nil && !ignoredFields[key] {
+			changes[key] = Change{After: value}
+		}
+	}
+	return changes
+}
+
+func toMap(v interface{}) map[string]interface{} {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	return m
+}
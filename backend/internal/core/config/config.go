@@ -2,17 +2,262 @@ package config
 
 import (
 	"log"
+	"net"
+	"strings"
+	"time"
+
+	"backend/internal/core/common"
 
 	"github.com/spf13/viper"
+	"golang.org/x/crypto/bcrypt"
 )
 
+// DefaultJWTSecret is only meant for local development; startup refuses it in production.
+const DefaultJWTSecret = "your_super_secret_key_change_me"
+
+type JWTConfig struct {
+	Secret     string
+	Issuer     string
+	Audience   string
+	TTL        time.Duration
+	RefreshTTL time.Duration
+	Algorithm  string
+	KeysDir    string // Only used by asymmetric algorithms (RS*, EdDSA)
+	ActiveKID  string
+}
+
+var JWT JWTConfig
+
+// LoginThrottleConfig controls backoff and lockout after failed logins.
+type LoginThrottleConfig struct {
+	BackoffAfter       int // Failures allowed before backoff starts
+	BackoffBase        time.Duration
+	BackoffMax         time.Duration
+	MaxAccountFailures int // Failures before the account is locked
+	MaxIPFailures      int // Failures before the client IP is locked
+	LockoutDuration    time.Duration
+	Window             time.Duration // Failures older than this are forgotten
+}
+
+var LoginThrottle LoginThrottleConfig
+
+// PasswordHashConfig selects how new password hashes are created. Existing hashes made
+// with other parameters keep working and are upgraded on the next login.
+type PasswordHashConfig struct {
+	Algorithm     string // bcrypt or argon2id
+	BcryptCost    int
+	Argon2Memory  uint32 // KiB
+	Argon2Time    uint32 // Iterations
+	Argon2Threads uint8
+}
+
+var PasswordHash PasswordHashConfig
+
+// PasswordPolicyConfig sets the rules new passwords must meet.
+type PasswordPolicyConfig struct {
+	MinLength      int
+	MinCharClasses int // Of lowercase, uppercase, digits and symbols, 0 disables the rule
+	CheckBreached  bool
+	BreachedDir    string // Pwned Passwords range files, see internal/core/validation/breached.go
+}
+
+var PasswordPolicy PasswordPolicyConfig
+
+type MailConfig struct {
+	Driver       string // smtp, file or log
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	FileDir      string
+}
+
+var Mail MailConfig
+
+// OIDCConfig configures sign-in with an external identity provider. Leaving Provider
+// empty disables it.
+type OIDCConfig struct {
+	Provider     string // github or oidc
+	Issuer       string // Only used by oidc, discovery is loaded from <issuer>/.well-known/openid-configuration
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string // This API's callback, registered with the provider
+	Scopes       string
+	FrontendURL  string // Where the browser is sent after the callback, the result is in the URL fragment
+}
+
+var OIDC OIDCConfig
+
+// PasswordResetURL is the frontend page reset links point to, the token is appended as ?token=
+var PasswordResetURL string
+
+// SetupRequireToken makes first-run setup require a one-time token printed to the log at startup.
+var SetupRequireToken bool
+
+// SettingsCacheTTL bounds how long system settings are served from memory.
+var SettingsCacheTTL time.Duration
+
+// DiarySchedulerInterval is how often scheduled diary entries are checked for publishing.
+var DiarySchedulerInterval time.Duration
+
+// TrustedProxies lists the CIDRs allowed to set X-Forwarded-For / X-Real-IP.
+var TrustedProxies []*net.IPNet
+
 func Init() {
 	viper.SetConfigFile(".env")
 	viper.AutomaticEnv() // Read from system env as well
 
+	viper.SetDefault("APP_ENV", common.EnvDev)
+	viper.SetDefault("JWT_SECRET", DefaultJWTSecret)
+	viper.SetDefault("JWT_ISSUER", common.ServiceName)
+	viper.SetDefault("JWT_AUDIENCE", common.ServiceName)
+	viper.SetDefault("JWT_TTL", "15m")
+	viper.SetDefault("JWT_REFRESH_TTL", "720h")
+	viper.SetDefault("JWT_ALGORITHM", "HS256")
+	viper.SetDefault("JWT_KEYS_DIR", "keys")
+	viper.SetDefault("LOGIN_BACKOFF_AFTER", 3)
+	viper.SetDefault("LOGIN_BACKOFF_BASE", "1s")
+	viper.SetDefault("LOGIN_BACKOFF_MAX", "5m")
+	viper.SetDefault("LOGIN_MAX_ACCOUNT_FAILURES", 10)
+	viper.SetDefault("LOGIN_MAX_IP_FAILURES", 50)
+	viper.SetDefault("LOGIN_LOCKOUT_DURATION", "15m")
+	viper.SetDefault("LOGIN_FAILURE_WINDOW", "1h")
+	viper.SetDefault("TRUSTED_PROXIES", "127.0.0.1/32,::1/128")
+	viper.SetDefault("PASSWORD_HASH_ALGORITHM", "bcrypt")
+	viper.SetDefault("BCRYPT_COST", 12)
+	viper.SetDefault("ARGON2_MEMORY_KIB", 19456)
+	viper.SetDefault("ARGON2_TIME", 2)
+	viper.SetDefault("ARGON2_THREADS", 1)
+	viper.SetDefault("PASSWORD_MIN_LENGTH", 10)
+	viper.SetDefault("PASSWORD_MIN_CHAR_CLASSES", 0)
+	viper.SetDefault("PASSWORD_CHECK_BREACHED", true)
+	viper.SetDefault("MAIL_DRIVER", "log")
+	viper.SetDefault("MAIL_FROM", "no-reply@localhost")
+	viper.SetDefault("SMTP_PORT", 587)
+	viper.SetDefault("MAIL_FILE_DIR", "tmp/mail")
+	viper.SetDefault("PASSWORD_RESET_URL", "http://localhost:5173/reset-password")
+	viper.SetDefault("SETTINGS_CACHE_TTL", "1m")
+	viper.SetDefault("DIARY_SCHEDULER_INTERVAL", "1m")
+	viper.SetDefault("OIDC_REDIRECT_URL", "http://localhost:8888/api/auth/oidc/callback")
+	viper.SetDefault("OIDC_SCOPES", "openid email profile")
+	viper.SetDefault("OIDC_FRONTEND_URL", "http://localhost:5173/auth/callback")
+
 	if err := viper.ReadInConfig(); err != nil {
 		log.Println("Warning: No .env file found or error reading it, relying on System Environment Variables.")
 	} else {
 		log.Println("Loaded configuration from .env")
 	}
+
+	JWT = JWTConfig{
+		Secret:     viper.GetString("JWT_SECRET"),
+		Issuer:     viper.GetString("JWT_ISSUER"),
+		Audience:   viper.GetString("JWT_AUDIENCE"),
+		TTL:        viper.GetDuration("JWT_TTL"),
+		RefreshTTL: viper.GetDuration("JWT_REFRESH_TTL"),
+		Algorithm:  viper.GetString("JWT_ALGORITHM"),
+		KeysDir:    viper.GetString("JWT_KEYS_DIR"),
+		ActiveKID:  viper.GetString("JWT_ACTIVE_KID"),
+	}
+
+	LoginThrottle = LoginThrottleConfig{
+		BackoffAfter:       viper.GetInt("LOGIN_BACKOFF_AFTER"),
+		BackoffBase:        viper.GetDuration("LOGIN_BACKOFF_BASE"),
+		BackoffMax:         viper.GetDuration("LOGIN_BACKOFF_MAX"),
+		MaxAccountFailures: viper.GetInt("LOGIN_MAX_ACCOUNT_FAILURES"),
+		MaxIPFailures:      viper.GetInt("LOGIN_MAX_IP_FAILURES"),
+		LockoutDuration:    viper.GetDuration("LOGIN_LOCKOUT_DURATION"),
+		Window:             viper.GetDuration("LOGIN_FAILURE_WINDOW"),
+	}
+
+	PasswordHash = PasswordHashConfig{
+		Algorithm:     viper.GetString("PASSWORD_HASH_ALGORITHM"),
+		BcryptCost:    viper.GetInt("BCRYPT_COST"),
+		Argon2Memory:  viper.GetUint32("ARGON2_MEMORY_KIB"),
+		Argon2Time:    viper.GetUint32("ARGON2_TIME"),
+		Argon2Threads: uint8(viper.GetUint("ARGON2_THREADS")),
+	}
+
+	PasswordPolicy = PasswordPolicyConfig{
+		MinLength:      viper.GetInt("PASSWORD_MIN_LENGTH"),
+		MinCharClasses: viper.GetInt("PASSWORD_MIN_CHAR_CLASSES"),
+		CheckBreached:  viper.GetBool("PASSWORD_CHECK_BREACHED"),
+		BreachedDir:    viper.GetString("PASSWORD_BREACHED_DIR"),
+	}
+
+	Mail = MailConfig{
+		Driver:       viper.GetString("MAIL_DRIVER"),
+		From:         viper.GetString("MAIL_FROM"),
+		SMTPHost:     viper.GetString("SMTP_HOST"),
+		SMTPPort:     viper.GetInt("SMTP_PORT"),
+		SMTPUsername: viper.GetString("SMTP_USERNAME"),
+		SMTPPassword: viper.GetString("SMTP_PASSWORD"),
+		FileDir:      viper.GetString("MAIL_FILE_DIR"),
+	}
+	PasswordResetURL = viper.GetString("PASSWORD_RESET_URL")
+	SetupRequireToken = viper.GetBool("SETUP_REQUIRE_TOKEN")
+	SettingsCacheTTL = viper.GetDuration("SETTINGS_CACHE_TTL")
+	DiarySchedulerInterval = viper.GetDuration("DIARY_SCHEDULER_INTERVAL")
+
+	OIDC = OIDCConfig{
+		Provider:     viper.GetString("OIDC_PROVIDER"),
+		Issuer:       viper.GetString("OIDC_ISSUER"),
+		ClientID:     viper.GetString("OIDC_CLIENT_ID"),
+		ClientSecret: viper.GetString("OIDC_CLIENT_SECRET"),
+		RedirectURL:  viper.GetString("OIDC_REDIRECT_URL"),
+		Scopes:       viper.GetString("OIDC_SCOPES"),
+		FrontendURL:  viper.GetString("OIDC_FRONTEND_URL"),
+	}
+
+	TrustedProxies = nil
+	for _, cidr := range strings.Split(viper.GetString("TRUSTED_PROXIES"), ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			log.Fatalf("invalid TRUSTED_PROXIES entry %q: %v", cidr, err)
+		}
+		TrustedProxies = append(TrustedProxies, ipNet)
+	}
+
+	if JWT.TTL <= 0 {
+		log.Fatalf("invalid JWT_TTL %q: must be a positive duration", viper.GetString("JWT_TTL"))
+	}
+	if JWT.RefreshTTL <= JWT.TTL {
+		log.Fatalf("invalid JWT_REFRESH_TTL %q: must be longer than JWT_TTL", viper.GetString("JWT_REFRESH_TTL"))
+	}
+	if DiarySchedulerInterval <= 0 {
+		log.Fatalf("invalid DIARY_SCHEDULER_INTERVAL %q: must be a positive duration", viper.GetString("DIARY_SCHEDULER_INTERVAL"))
+	}
+
+	switch PasswordHash.Algorithm {
+	case "bcrypt":
+		if PasswordHash.BcryptCost < bcrypt.MinCost || PasswordHash.BcryptCost > bcrypt.MaxCost {
+			log.Fatalf("invalid BCRYPT_COST %d: must be between %d and %d", PasswordHash.BcryptCost, bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case "argon2id":
+		if PasswordHash.Argon2Memory == 0 || PasswordHash.Argon2Time == 0 || PasswordHash.Argon2Threads == 0 {
+			log.Fatal("ARGON2_MEMORY_KIB, ARGON2_TIME and ARGON2_THREADS must be positive")
+		}
+	default:
+		log.Fatalf("invalid PASSWORD_HASH_ALGORITHM %q: expected bcrypt or argon2id", PasswordHash.Algorithm)
+	}
+
+	// The log driver prints every email, including password reset links, to the server log
+	if IsProd() && (Mail.Driver == "log" || Mail.Driver == "") {
+		log.Fatal("MAIL_DRIVER must be smtp or file in production")
+	}
+
+	if strings.HasPrefix(JWT.Algorithm, "HS") && JWT.Secret == DefaultJWTSecret {
+		if IsProd() {
+			log.Fatal("JWT_SECRET must be set to a non-default value in production")
+		}
+		log.Println("Warning: using the default JWT_SECRET, do not use this in production.")
+	}
+}
+
+func IsProd() bool {
+	return viper.GetString("APP_ENV") == common.EnvProd
 }
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"sync"
+)
+
+type memoryTxKey struct{}
+
+// Snapshotter is implemented by in-memory repositories. Snapshot captures the stored
+// data and returns a function that puts it back.
+type Snapshotter interface {
+	Snapshot() (restore func())
+}
+
+// MemoryTransactor is the Transactor for in-memory repositories. Transactions run one
+// at a time, which makes Lock unnecessary. When fn fails, the repositories passed to
+// NewMemoryTransactor are restored to their state before the transaction, others keep
+// whatever fn wrote.
+type MemoryTransactor struct {
+	mu    sync.Mutex
+	repos []Snapshotter
+}
+
+var _ Transactor = (*MemoryTransactor)(nil)
+
+func NewMemoryTransactor(repos ...Snapshotter) *MemoryTransactor {
+	return &MemoryTransactor{repos: repos}
+}
+
+func (t *MemoryTransactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	// Nested calls run inside the outer transaction and, like savepoints, only undo
+	// their own writes
+	if ctx.Value(memoryTxKey{}) != t {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		ctx = context.WithValue(ctx, memoryTxKey{}, t)
+	}
+
+	restores := make([]func(), len(t.repos))
+	for i, repo := range t.repos {
+		restores[i] = repo.Snapshot()
+	}
+	err := fn(ctx)
+	if err != nil {
+		for _, restore := range restores {
+			restore()
+		}
+	}
+	return err
+}
+
+func (t *MemoryTransactor) Lock(ctx context.Context, key int64) error {
+	return nil
+}
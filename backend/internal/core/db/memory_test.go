@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// counter is the smallest Snapshotter, a single value that transactions change.
+type counter struct {
+	value int
+}
+
+func (c *counter) Snapshot() func() {
+	value := c.value
+	return func() { c.value = value }
+}
+
+func TestMemoryTransactorRestoresOnError(t *testing.T) {
+	c := &counter{}
+	tx := NewMemoryTransactor(c)
+	failure := errors.New("failure")
+
+	err := tx.Transaction(context.Background(), func(ctx context.Context) error {
+		c.value = 1
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected the error of fn, got %v", err)
+	}
+	if c.value != 0 {
+		t.Fatalf("expected the write to be undone, got %d", c.value)
+	}
+
+	if err := tx.Transaction(context.Background(), func(ctx context.Context) error {
+		c.value = 2
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if c.value != 2 {
+		t.Fatalf("expected the write to be kept, got %d", c.value)
+	}
+}
+
+func TestMemoryTransactorNestedRestoresOnlyInner(t *testing.T) {
+	c := &counter{}
+	tx := NewMemoryTransactor(c)
+
+	err := tx.Transaction(context.Background(), func(ctx context.Context) error {
+		c.value = 1
+		inner := tx.Transaction(ctx, func(ctx context.Context) error {
+			c.value = 2
+			return errors.New("failure")
+		})
+		if inner == nil {
+			t.Fatal("expected the inner transaction to fail")
+		}
+		if c.value != 1 {
+			t.Fatalf("expected the inner write to be undone, got %d", c.value)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.value != 1 {
+		t.Fatalf("expected the outer write to be kept, got %d", c.value)
+	}
+}
@@ -23,7 +23,8 @@ func Init() {
 
 	var err error
 	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger:         logger.Default.LogMode(logger.Info),
+		TranslateError: true, // Unique violations surface as gorm.ErrDuplicatedKey, as in the memory repositories
 	})
 
 	if err != nil {
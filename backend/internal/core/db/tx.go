@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// Transaction runs fn in a database transaction. Repositories called with the context
+// passed to fn take part in it through Conn. The transaction is rolled back if fn
+// returns an error.
+func Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return Conn(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// Conn returns the transaction carried by ctx, or the connection pool outside of one.
+// Repositories use it for every query.
+func Conn(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return DB.WithContext(ctx)
+}
+
+// AdvisoryXactLock takes a Postgres advisory lock that is held until the transaction in
+// ctx ends. It must be called inside Transaction.
+func AdvisoryXactLock(ctx context.Context, key int64) error {
+	return Conn(ctx).Exec("SELECT pg_advisory_xact_lock(?)", key).Error
+}
+
+// InTransaction reports whether ctx carries a transaction started by Transaction.
+func InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return ok
+}
+
+// Transactor runs a unit of work atomically. Services take one instead of calling
+// Transaction directly, so they can also run against in-memory repositories.
+type Transactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+	// Lock takes an exclusive lock on key that is held until the transaction in ctx ends.
+	Lock(ctx context.Context, key int64) error
+}
+
+// PostgresTransactor implements Transactor with Transaction and AdvisoryXactLock.
+type PostgresTransactor struct{}
+
+var _ Transactor = (*PostgresTransactor)(nil)
+
+func NewPostgresTransactor() *PostgresTransactor {
+	return &PostgresTransactor{}
+}
+
+func (PostgresTransactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return Transaction(ctx, fn)
+}
+
+func (PostgresTransactor) Lock(ctx context.Context, key int64) error {
+	return AdvisoryXactLock(ctx, key)
+}
@@ -0,0 +1,50 @@
+// Package events lets modules react to what happens in other modules without importing
+// them. Publishers name the event and attach a payload; subscribers register for names
+// at startup.
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+type Event struct {
+	Name    string
+	Payload any // Documented next to the event name by the publishing module
+}
+
+type Handler func(ctx context.Context, event Event)
+
+var (
+	mu       sync.RWMutex
+	handlers = make(map[string][]Handler)
+)
+
+// Subscribe calls h for every event with the given name.
+func Subscribe(name string, h Handler) {
+	mu.Lock()
+	defer mu.Unlock()
+	handlers[name] = append(handlers[name], h)
+}
+
+// Publish calls the subscribers of event in the caller's goroutine, in the order they
+// subscribed. A panicking subscriber is logged and doesn't stop the others.
+func Publish(ctx context.Context, event Event) {
+	mu.RLock()
+	subscribers := handlers[event.Name]
+	mu.RUnlock()
+
+	for _, h := range subscribers {
+		call(ctx, h, event)
+	}
+}
+
+func call(ctx context.Context, h Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("events: subscriber of %s panicked: %v", event.Name, r)
+		}
+	}()
+	h(ctx, event)
+}
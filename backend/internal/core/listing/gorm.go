@@ -0,0 +1,56 @@
+package listing
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Where applies the filters and the date range to a query.
+func (p Params) Where(db *gorm.DB) *gorm.DB {
+	for _, cond := range p.Conditions {
+		column := clause.Column{Name: cond.Column}
+		if cond.Kind == FilterContains {
+			db = db.Where(clause.Expr{SQL: "? = ANY(?)", Vars: []any{cond.Value, column}})
+		} else {
+			db = db.Where(clause.Eq{Column: column, Value: cond.Value})
+		}
+	}
+	if p.From != nil {
+		db = db.Where(clause.Gte{Column: clause.Column{Name: p.DateColumn}, Value: *p.From})
+	}
+	if p.To != nil {
+		db = db.Where(clause.Lt{Column: clause.Column{Name: p.DateColumn}, Value: *p.To})
+	}
+	return db
+}
+
+// Paginate applies the sort order and selects the requested page. The ID breaks ties, so
+// pages don't overlap when sort values repeat.
+func (p Params) Paginate(db *gorm.DB) *gorm.DB {
+	for _, order := range p.Sort {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: order.Column}, Desc: order.Desc})
+	}
+	db = db.Order("id")
+	if p.Limit > 0 {
+		db = db.Limit(p.Limit)
+	}
+	if p.Offset > 0 {
+		db = db.Offset(p.Offset)
+	}
+	return db
+}
+
+// Find loads the requested page into dest and returns the total number of matches.
+// query may already carry conditions of its own, e.g. to hide private records.
+func Find[T any](query *gorm.DB, p Params, dest *[]T) (int64, error) {
+	query = p.Where(query.Model(new(T))).Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return 0, err
+	}
+	if err := p.Paginate(query).Find(dest).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,47 @@
+package listing
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// FromRequest parses the list parameters of a request, see Parse.
+func FromRequest(ctx *app.RequestContext, spec Spec) (Params, error) {
+	return Parse(ctx.Query, spec)
+}
+
+// SetHeaders describes the page in the response headers, leaving the body a plain
+// array: X-Total-Count holds the number of matches and, when a limit applies, Link
+// (RFC 8288) the URLs of the first, previous, next and last pages.
+func SetHeaders(ctx *app.RequestContext, p Params, total int64) {
+	ctx.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	if p.Limit == 0 {
+		return
+	}
+
+	u, err := url.Parse(string(ctx.Request.URI().RequestURI()))
+	if err != nil {
+		return
+	}
+	pageURL := func(offset int) string {
+		q := u.Query()
+		q.Set("limit", strconv.Itoa(p.Limit))
+		q.Set("offset", strconv.Itoa(offset))
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+
+	last := max(0, int(total-1)/p.Limit*p.Limit)
+	links := []string{`<` + pageURL(0) + `>; rel="first"`}
+	if p.Offset > 0 {
+		links = append(links, `<`+pageURL(min(max(0, p.Offset-p.Limit), last))+`>; rel="prev"`)
+	}
+	if int64(p.Offset+p.Limit) < total {
+		links = append(links, `<`+pageURL(p.Offset+p.Limit)+`>; rel="next"`)
+	}
+	links = append(links, `<`+pageURL(last)+`>; rel="last"`)
+	ctx.Header("Link", strings.Join(links, ", "))
+}
@@ -0,0 +1,148 @@
+// Package listing turns the query parameters of list endpoints into paging, sorting and
+// filtering that repositories apply, so every list endpoint accepts the same parameters:
+//
+//	limit, offset   page size and number of matches to skip; without limit every match
+//	                is returned, unless the endpoint's Spec sets a default
+//	sort            comma-separated fields, "-" for descending, e.g. sort=-date,title
+//	from, to        range over the endpoint's date field, RFC 3339 or YYYY-MM-DD; to is exclusive
+//	<filter>=value  the filters declared in the endpoint's Spec, e.g. technology=go
+package listing
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/core/validation"
+)
+
+const MaxLimit = 200
+
+type FilterKind int
+
+const (
+	FilterEqual    FilterKind = iota // Column equals the value
+	FilterBool                       // Boolean column, the value must parse as one
+	FilterContains                   // Array column contains the value
+)
+
+// Filter maps a query parameter to a column.
+type Filter struct {
+	Param  string
+	Column string
+	Kind   FilterKind
+}
+
+// Spec declares what a list endpoint supports. Columns are named as in the JSON form of
+// the records, which in this codebase matches the database columns.
+type Spec struct {
+	Sorts       []string // Columns that may be sorted by
+	DefaultSort string   // Used without ?sort=, in the same syntax
+	Filters     []Filter
+	DateColumn  string // Column ranged by ?from= and ?to=, empty to disable
+	// Used without ?limit=, 0 returns every match. Endpoints whose result sets can grow
+	// without bound, like search, set one.
+	DefaultLimit int
+}
+
+type Order struct {
+	Column string
+	Desc   bool
+}
+
+type Condition struct {
+	Column string
+	Kind   FilterKind
+	Value  any // string, or bool for FilterBool
+}
+
+// Params is a parsed list request.
+type Params struct {
+	Limit      int // 0 for no limit
+	Offset     int
+	Sort       []Order
+	Conditions []Condition
+	DateColumn string
+	From       *time.Time
+	To         *time.Time
+}
+
+// Parse reads the parameters through get, usually the request's Query method. Invalid
+// values are reported as validation.Errors keyed by parameter.
+func Parse(get func(key string) string, spec Spec) (Params, error) {
+	errs := validation.Errors{}
+	p := Params{Limit: spec.DefaultLimit, DateColumn: spec.DateColumn}
+
+	if raw := get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			errs.Add("limit", "must be between 1 and "+strconv.Itoa(MaxLimit))
+		}
+		p.Limit = limit
+	}
+	if raw := get("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			errs.Add("offset", "must be zero or a positive number")
+		}
+		p.Offset = offset
+	}
+
+	sort := get("sort")
+	if sort == "" {
+		sort = spec.DefaultSort
+	}
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		order := Order{Column: strings.TrimPrefix(field, "-"), Desc: strings.HasPrefix(field, "-")}
+		if !slices.Contains(spec.Sorts, order.Column) {
+			errs.Add("sort", "can only use "+strings.Join(spec.Sorts, ", "))
+			continue
+		}
+		p.Sort = append(p.Sort, order)
+	}
+
+	for _, filter := range spec.Filters {
+		raw := get(filter.Param)
+		if raw == "" {
+			continue
+		}
+		cond := Condition{Column: filter.Column, Kind: filter.Kind, Value: raw}
+		if filter.Kind == FilterBool {
+			value, err := strconv.ParseBool(raw)
+			if err != nil {
+				errs.Add(filter.Param, "must be true or false")
+			}
+			cond.Value = value
+		}
+		p.Conditions = append(p.Conditions, cond)
+	}
+
+	if spec.DateColumn != "" {
+		p.From = parseDate(get, "from", errs)
+		p.To = parseDate(get, "to", errs)
+	}
+
+	if err := errs.Err(); err != nil {
+		return Params{}, err
+	}
+	return p, nil
+}
+
+func parseDate(get func(string) string, param string, errs validation.Errors) *time.Time {
+	raw := get(param)
+	if raw == "" {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return &t
+		}
+	}
+	errs.Add(param, "must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	return nil
+}
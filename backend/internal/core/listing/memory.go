@@ -0,0 +1,144 @@
+package listing
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Slice applies p to records held in memory, for the in-memory repositories. Columns
+// are looked up in the JSON form of each record. It returns the page and the total
+// number of matches.
+func Slice[T any](items []T, p Params) ([]T, int64) {
+	type row struct {
+		item   T
+		fields map[string]any
+	}
+
+	var rows []row
+	for _, item := range items {
+		fields := jsonFields(item)
+		if p.matches(fields) {
+			rows = append(rows, row{item, fields})
+		}
+	}
+
+	orders := append(slices.Clone(p.Sort), Order{Column: "id"})
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, order := range orders {
+			c := compare(rows[i].fields[order.Column], rows[j].fields[order.Column])
+			if c != 0 {
+				return (c < 0) != order.Desc
+			}
+		}
+		return false
+	})
+
+	total := int64(len(rows))
+	rows = rows[min(p.Offset, len(rows)):]
+	if p.Limit > 0 {
+		rows = rows[:min(p.Limit, len(rows))]
+	}
+
+	page := make([]T, len(rows))
+	for i, r := range rows {
+		page[i] = r.item
+	}
+	return page, total
+}
+
+func (p Params) matches(fields map[string]any) bool {
+	for _, cond := range p.Conditions {
+		value := fields[cond.Column]
+		if cond.Kind == FilterContains {
+			values, _ := value.([]any)
+			found := false
+			for _, v := range values {
+				found = found || fmt.Sprint(v) == cond.Value
+			}
+			if !found {
+				return false
+			}
+		} else if fmt.Sprint(value) != fmt.Sprint(cond.Value) {
+			return false
+		}
+	}
+
+	if p.From != nil || p.To != nil {
+		t, ok := asTime(fields[p.DateColumn])
+		if !ok || (p.From != nil && t.Before(*p.From)) || (p.To != nil && !t.Before(*p.To)) {
+			return false
+		}
+	}
+	return true
+}
+
+func jsonFields(item any) map[string]any {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+// compare orders JSON values of the same column, with nil first like NULLS FIRST.
+func compare(a, b any) int {
+	if ta, ok := asTime(a); ok {
+		if tb, ok := asTime(b); ok {
+			return ta.Compare(tb)
+		}
+	}
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	switch a := a.(type) {
+	case float64:
+		b, _ := b.(float64)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case bool:
+		b, _ := b.(bool)
+		switch {
+		case a == b:
+			return 0
+		case !a:
+			return -1
+		}
+		return 1
+	}
+
+	sa, sb := fmt.Sprint(a), fmt.Sprint(b)
+	switch {
+	case sa < sb:
+		return -1
+	case sa > sb:
+		return 1
+	}
+	return 0
+}
+
+func asTime(v any) (time.Time, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	return t, err == nil
+}
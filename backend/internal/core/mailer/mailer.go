@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"backend/internal/core/config"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string // Plain text
+}
+
+// Mailer delivers transactional emails such as password resets.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Default is the mailer selected by MAIL_DRIVER, set by Init.
+var Default Mailer
+
+func Init() {
+	m, err := New(config.Mail)
+	if err != nil {
+		log.Fatalf("failed to init mailer: %v", err)
+	}
+	Default = m
+}
+
+func New(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg), nil
+	case "file":
+		return NewFileMailer(cfg.From, cfg.FileDir), nil
+	case "log", "":
+		return NewLogMailer(), nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q", cfg.Driver)
+	}
+}
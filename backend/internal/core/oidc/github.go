@@ -0,0 +1,85 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"backend/internal/core/config"
+)
+
+// GitHub speaks plain OAuth2 rather than OpenID Connect, so the identity comes from its API.
+const (
+	githubAuthorizeURL = "https://github.com/login/oauth/authorize"
+	githubTokenURL     = "https://github.com/login/oauth/access_token"
+	githubAPIURL       = "https://api.github.com"
+)
+
+type githubProvider struct {
+	cfg config.OIDCConfig
+}
+
+func newGitHubProvider(cfg config.OIDCConfig) *githubProvider {
+	return &githubProvider{cfg: cfg}
+}
+
+func (p *githubProvider) Name() string {
+	return "github"
+}
+
+func (p *githubProvider) AuthCodeURL(state, nonce, codeChallenge string) string {
+	params := url.Values{}
+	params.Set("client_id", p.cfg.ClientID)
+	params.Set("redirect_uri", p.cfg.RedirectURL)
+	params.Set("scope", "read:user user:email")
+	params.Set("state", state)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+	return githubAuthorizeURL + "?" + params.Encode()
+}
+
+func (p *githubProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error) {
+	tokens, err := exchangeCode(ctx, githubTokenURL, p.cfg, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := getJSON(ctx, githubAPIURL+"/user", tokens.AccessToken, &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, errors.New("github: could not load user")
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, githubAPIURL+"/user/emails", tokens.AccessToken, &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Provider: p.Name(),
+		Subject:  fmt.Sprint(user.ID),
+		Name:     user.Name,
+	}
+	for _, e := range emails {
+		if e.Primary {
+			identity.Email = e.Email
+			identity.EmailVerified = e.Verified
+			break
+		}
+	}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+	return identity, nil
+}
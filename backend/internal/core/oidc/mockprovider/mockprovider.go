@@ -0,0 +1,281 @@
+// Package mockprovider is a minimal OpenID Connect provider for local development and
+// tests. It accepts any email without a password and signs ID tokens with a key that is
+// generated at startup. Never expose it outside a development machine.
+package mockprovider
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"html/template"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	keyID   = "mock"
+	codeTTL = time.Minute
+)
+
+type Config struct {
+	Issuer       string // Base URL the provider is reachable at
+	ClientID     string
+	ClientSecret string
+}
+
+type authCode struct {
+	clientID      string
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	email         string
+	emailVerified bool
+	expiresAt     time.Time
+}
+
+type Provider struct {
+	cfg Config
+	key *rsa.PrivateKey
+
+	mu     sync.Mutex
+	codes  map[string]authCode
+	access map[string]authCode // Access token to the login it was issued for
+}
+
+func New(cfg Config) (*Provider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Issuer = strings.TrimSuffix(cfg.Issuer, "/")
+	return &Provider{
+		cfg:    cfg,
+		key:    key,
+		codes:  map[string]authCode{},
+		access: map[string]authCode{},
+	}, nil
+}
+
+func (p *Provider) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	mux.HandleFunc("/userinfo", p.userinfo)
+	mux.HandleFunc("/jwks", p.jwks)
+	return mux
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.cfg.Issuer,
+		"authorization_endpoint":                p.cfg.Issuer + "/authorize",
+		"token_endpoint":                        p.cfg.Issuer + "/token",
+		"userinfo_endpoint":                     p.cfg.Issuer + "/userinfo",
+		"jwks_uri":                              p.cfg.Issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+var loginPage = template.Must(template.New("login").Parse(`<!doctype html>
+<title>Mock identity provider</title>
+<h1>Mock identity provider</h1>
+<form method="post">
+  {{range $name, $value := .Params}}<input type="hidden" name="{{$name}}" value="{{index $value 0}}">
+  {{end}}<label>Email <input name="email" type="email" value="{{.Email}}" required autofocus></label>
+  <label><input name="email_verified" type="checkbox" value="true" checked> Email verified</label>
+  <button type="submit">Sign in</button>
+</form>
+`))
+
+// authorize shows a login form on GET and issues a code on POST. Passing login_hint on
+// the GET skips the form, which is convenient for scripted tests.
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.Form.Get("client_id") != p.cfg.ClientID {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+	redirectURI := r.Form.Get("redirect_uri")
+	if _, err := url.ParseRequestURI(redirectURI); err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if r.Form.Get("code_challenge") == "" || r.Form.Get("code_challenge_method") != "S256" {
+		http.Error(w, "PKCE with S256 is required", http.StatusBadRequest)
+		return
+	}
+
+	email := r.Form.Get("email")
+	emailVerified := r.Form.Get("email_verified") == "true"
+	if r.Method == http.MethodGet {
+		if hint := r.Form.Get("login_hint"); hint != "" {
+			email, emailVerified = hint, true
+		} else {
+			params := r.URL.Query()
+			_ = loginPage.Execute(w, map[string]interface{}{"Params": params, "Email": "owner@example.com"})
+			return
+		}
+	}
+	if email == "" {
+		http.Error(w, "email is required", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+	p.mu.Lock()
+	p.codes[code] = authCode{
+		clientID:      p.cfg.ClientID,
+		redirectURI:   redirectURI,
+		codeChallenge: r.Form.Get("code_challenge"),
+		nonce:         r.Form.Get("nonce"),
+		email:         email,
+		emailVerified: emailVerified,
+		expiresAt:     time.Now().Add(codeTTL),
+	}
+	p.mu.Unlock()
+
+	target, _ := url.Parse(redirectURI)
+	query := target.Query()
+	query.Set("code", code)
+	query.Set("state", r.Form.Get("state"))
+	target.RawQuery = query.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.Form.Get("client_id"), r.Form.Get("client_secret")
+	}
+	if clientID != p.cfg.ClientID || clientSecret != p.cfg.ClientSecret {
+		tokenError(w, "invalid_client")
+		return
+	}
+
+	p.mu.Lock()
+	code, ok := p.codes[r.Form.Get("code")]
+	delete(p.codes, r.Form.Get("code"))
+	p.mu.Unlock()
+
+	switch {
+	case r.Form.Get("grant_type") != "authorization_code",
+		!ok, time.Now().After(code.expiresAt),
+		code.redirectURI != r.Form.Get("redirect_uri"),
+		code.codeChallenge != challenge(r.Form.Get("code_verifier")):
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            p.cfg.Issuer,
+		"aud":            code.clientID,
+		"sub":            subject(code.email),
+		"email":          code.email,
+		"email_verified": code.emailVerified,
+		"name":           strings.Split(code.email, "@")[0],
+		"nonce":          code.nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+	})
+	idToken.Header["kid"] = keyID
+	signed, err := idToken.SignedString(p.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	accessToken := randomString()
+	p.mu.Lock()
+	p.access[accessToken] = code
+	p.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     signed,
+	})
+}
+
+func (p *Provider) userinfo(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	code, ok := p.access[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+	p.mu.Unlock()
+	if !ok {
+		http.Error(w, "invalid access token", http.StatusUnauthorized)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"sub":            subject(code.email),
+		"email":          code.email,
+		"email_verified": code.emailVerified,
+	})
+}
+
+func (p *Provider) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := p.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+// subject is stable per email, so logging in twice with the same address yields the same identity.
+func subject(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(email)))
+	return "mock-" + base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+func challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
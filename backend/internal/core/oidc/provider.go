@@ -0,0 +1,120 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"backend/internal/core/config"
+)
+
+// Identity is the verified result of a login at the external provider.
+type Identity struct {
+	Provider      string
+	Subject       string // Stable user id at the provider
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider runs the authorization code flow with PKCE against an identity provider.
+type Provider interface {
+	Name() string
+	AuthCodeURL(state, nonce, codeChallenge string) string
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error)
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// NewProvider builds the provider selected by OIDC_PROVIDER.
+func NewProvider(ctx context.Context, cfg config.OIDCConfig) (Provider, error) {
+	switch cfg.Provider {
+	case "github":
+		return newGitHubProvider(cfg), nil
+	case "oidc":
+		return newGenericProvider(ctx, cfg)
+	default:
+		return nil, fmt.Errorf("unknown OIDC_PROVIDER %q", cfg.Provider)
+	}
+}
+
+// CodeChallenge derives the S256 PKCE challenge for a verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// tokenResponse is the subset of the OAuth2 token endpoint response we use.
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func exchangeCode(ctx context.Context, tokenURL string, cfg config.OIDCConfig, code, codeVerifier string) (*tokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", cfg.RedirectURL)
+	form.Set("client_id", cfg.ClientID)
+	form.Set("client_secret", cfg.ClientSecret)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var tokens tokenResponse
+	if err := doJSON(req, &tokens); err != nil {
+		return nil, err
+	}
+	if tokens.Error != "" {
+		return nil, fmt.Errorf("token exchange failed: %s %s", tokens.Error, tokens.ErrorDescription)
+	}
+	if tokens.AccessToken == "" {
+		return nil, errors.New("token exchange returned no access token")
+	}
+	return &tokens, nil
+}
+
+func getJSON(ctx context.Context, endpoint, accessToken string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return doJSON(req, out)
+}
+
+func doJSON(req *http.Request, out interface{}) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	// Token endpoints report OAuth errors with a 400 and a JSON body, let the caller see it
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusBadRequest {
+		return fmt.Errorf("%s %s: unexpected status %d", req.Method, req.URL.Redacted(), resp.StatusCode)
+	}
+	return json.Unmarshal(body, out)
+}
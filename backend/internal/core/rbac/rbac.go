@@ -0,0 +1,66 @@
+package rbac
+
+// Roles a CMS user can hold, from most to least privileged
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleAuthor = "author"
+	RoleViewer = "viewer"
+)
+
+type Permission string
+
+const (
+	PermDiaryWrite    Permission = "diary:write"
+	PermDiaryDelete   Permission = "diary:delete"
+	PermProjectWrite  Permission = "project:write"
+	PermProjectDelete Permission = "project:delete"
+	PermResumeWrite   Permission = "resume:write"
+	PermSocialWrite   Permission = "social:write"
+	PermManageUsers   Permission = "users:manage"
+	PermManageSystem  Permission = "system:manage"
+	PermAuditRead     Permission = "audit:read"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleOwner: {
+		PermDiaryWrite, PermDiaryDelete,
+		PermProjectWrite, PermProjectDelete,
+		PermResumeWrite, PermSocialWrite,
+		PermManageUsers, PermManageSystem,
+		PermAuditRead,
+	},
+	RoleEditor: {
+		PermDiaryWrite, PermDiaryDelete,
+		PermProjectWrite, PermProjectDelete,
+		PermResumeWrite, PermSocialWrite,
+	},
+	RoleAuthor: {
+		PermDiaryWrite,
+		PermProjectWrite,
+	},
+	RoleViewer: {},
+}
+
+// Identity is the caller behind a personal access token, resolved by the auth module
+// for the auth middleware.
+type Identity struct {
+	UserID uint
+	Role   string
+	Scopes []string
+}
+
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Can reports whether the role has been granted the permission.
+func Can(role string, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
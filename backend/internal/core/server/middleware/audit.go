@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"context"
+
+	"backend/internal/core/audit"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// AuditContext passes the client IP and user agent down to services for the audit log.
+// The acting user is added by JWTAuth and OptionalAuth.
+func AuditContext() app.HandlerFunc {
+	return func(c context.Context, ctx *app.RequestContext) {
+		ctx.Next(audit.WithClient(c, audit.Client{
+			IP:        ctx.ClientIP(),
+			UserAgent: string(ctx.UserAgent()),
+		}))
+	}
+}
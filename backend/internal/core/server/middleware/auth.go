@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"backend/internal/core/audit"
+	"backend/internal/core/rbac"
+	"backend/internal/core/utils"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Keys stored in the RequestContext by the auth middleware
+const (
+	ContextUserID          = "userID"
+	ContextRole            = "role"
+	ContextIsAuthenticated = "isAuthenticated"
+	ContextAuthMethod      = "authMethod"
+	ContextScopes          = "scopes" // Only set for API tokens
+	ContextCanSeePrivate   = "canSeePrivate"
+)
+
+// Values of ContextAuthMethod
+const (
+	AuthMethodJWT      = "jwt"
+	AuthMethodAPIToken = "api_token"
+)
+
+// APITokenValidator resolves personal access tokens, which are accepted alongside JWTs.
+type APITokenValidator interface {
+	IsAPIToken(token string) bool
+	ValidateAPIToken(ctx context.Context, token string) (*rbac.Identity, error)
+}
+
+var apiTokens APITokenValidator
+
+// UseAPITokens enables personal access tokens in JWTAuth and OptionalAuth.
+func UseAPITokens(v APITokenValidator) {
+	apiTokens = v
+}
+
+// JWTAuth rejects requests without a valid "Authorization: Bearer <token>" header.
+// The token is either a JWT or, if enabled, a personal access token.
+func JWTAuth() app.HandlerFunc {
+	return func(c context.Context, ctx *app.RequestContext) {
+		tokenString, ok := bearerToken(ctx)
+		if !ok {
+			ctx.AbortWithStatusJSON(consts.StatusUnauthorized, map[string]string{"error": "Missing or malformed authorization header"})
+			return
+		}
+
+		if err := authenticate(c, ctx, tokenString); err != nil {
+			ctx.AbortWithStatusJSON(consts.StatusUnauthorized, map[string]string{"error": tokenErrorMessage(err)})
+			return
+		}
+
+		ctx.Next(audit.WithActor(c, ctx.GetUint(ContextUserID)))
+	}
+}
+
+// OptionalAuth marks the request as authenticated when a valid bearer token is
+// present, but lets anonymous callers through so public routes can still be served.
+// Public routes must check ContextCanSeePrivate, not ContextIsAuthenticated, before
+// showing private or unpublished content: any registered viewer is authenticated.
+func OptionalAuth() app.HandlerFunc {
+	return func(c context.Context, ctx *app.RequestContext) {
+		ctx.Set(ContextIsAuthenticated, false)
+		ctx.Set(ContextCanSeePrivate, false)
+
+		if tokenString, ok := bearerToken(ctx); ok {
+			if err := authenticate(c, ctx, tokenString); err == nil {
+				c = audit.WithActor(c, ctx.GetUint(ContextUserID))
+			}
+		}
+
+		ctx.Next(c)
+	}
+}
+
+// SessionOnly rejects API tokens on routes that manage the account itself, such as
+// changing the password or issuing new tokens. It must run after JWTAuth.
+func SessionOnly() app.HandlerFunc {
+	return func(c context.Context, ctx *app.RequestContext) {
+		if ctx.GetString(ContextAuthMethod) != AuthMethodJWT {
+			ctx.AbortWithStatusJSON(consts.StatusForbidden, map[string]string{"error": "This action requires an interactive login"})
+			return
+		}
+		ctx.Next(c)
+	}
+}
+
+func authenticate(c context.Context, ctx *app.RequestContext, tokenString string) error {
+	if apiTokens != nil && apiTokens.IsAPIToken(tokenString) {
+		identity, err := apiTokens.ValidateAPIToken(c, tokenString)
+		if err != nil {
+			return err
+		}
+		ctx.Set(ContextUserID, identity.UserID)
+		ctx.Set(ContextRole, identity.Role)
+		ctx.Set(ContextScopes, identity.Scopes)
+		ctx.Set(ContextAuthMethod, AuthMethodAPIToken)
+		ctx.Set(ContextIsAuthenticated, true)
+		setCanSeePrivate(ctx)
+		return nil
+	}
+
+	claims, err := utils.ParseToken(tokenString)
+	if err != nil {
+		return err
+	}
+	ctx.Set(ContextUserID, claims.UserID)
+	ctx.Set(ContextRole, claims.Role)
+	ctx.Set(ContextAuthMethod, AuthMethodJWT)
+	ctx.Set(ContextIsAuthenticated, true)
+	setCanSeePrivate(ctx)
+	return nil
+}
+
+// setCanSeePrivate lets callers who may write diary entries read the private and
+// unpublished ones on public routes, as long as their API token has the diary scope.
+func setCanSeePrivate(ctx *app.RequestContext) {
+	ctx.Set(ContextCanSeePrivate, rbac.Can(ctx.GetString(ContextRole), rbac.PermDiaryWrite) && hasScope(ctx, string(rbac.PermDiaryWrite)))
+}
+
+// hasScope reports whether an API token caller was granted perm. Session callers are
+// only limited by their role.
+func hasScope(ctx *app.RequestContext, perm string) bool {
+	if ctx.GetString(ContextAuthMethod) != AuthMethodAPIToken {
+		return true
+	}
+	return slices.Contains(ctx.GetStringSlice(ContextScopes), perm)
+}
+
+func bearerToken(ctx *app.RequestContext) (string, bool) {
+	header := string(ctx.GetHeader("Authorization"))
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func tokenErrorMessage(err error) string {
+	var validationErr *jwt.ValidationError
+	if errors.As(err, &validationErr) && validationErr.Errors&jwt.ValidationErrorExpired != 0 {
+		return "Token expired"
+	}
+	return "Invalid token"
+}
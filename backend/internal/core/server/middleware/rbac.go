@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"context"
+
+	"backend/internal/core/rbac"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// RequirePermission rejects callers whose role lacks the permission, or whose API token
+// wasn't granted it as a scope. It must run after JWTAuth.
+func RequirePermission(perm rbac.Permission) app.HandlerFunc {
+	return func(c context.Context, ctx *app.RequestContext) {
+		if !rbac.Can(ctx.GetString(ContextRole), perm) {
+			ctx.AbortWithStatusJSON(consts.StatusForbidden, map[string]string{"error": "Insufficient permissions"})
+			return
+		}
+		if !hasScope(ctx, string(perm)) {
+			ctx.AbortWithStatusJSON(consts.StatusForbidden, map[string]string{"error": "API token lacks the required scope"})
+			return
+		}
+		ctx.Next(c)
+	}
+}
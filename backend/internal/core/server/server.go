@@ -1,8 +1,11 @@
 package server
 
 import (
+	"backend/internal/core/config"
 	"backend/internal/core/server/middleware"
 
+	"github.com/cloudwego/hertz/pkg/app"
+
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/hertz-contrib/cors"
 	"github.com/hertz-contrib/gzip"
@@ -11,17 +14,24 @@ import (
 func NewServer() *server.Hertz {
 	h := server.Default(server.WithHostPorts(":8888"))
 
+	// Only honour forwarded client IPs from trusted reverse proxies
+	h.SetClientIPFunc(app.ClientIPWithOption(app.ClientIPOptions{
+		RemoteIPHeaders: []string{"X-Forwarded-For", "X-Real-IP"},
+		TrustedCIDRs:    config.TrustedProxies,
+	}))
+
 	// Middleware
 	h.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173", "http://localhost:3000"}, // Allow frontend
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "X-Total-Count", "Link"},
 		AllowCredentials: true,
 	}))
 	h.Use(gzip.Gzip(gzip.DefaultCompression))
 	h.Use(middleware.SecurityHeaders())
 	h.Use(middleware.RateLimiter())
+	h.Use(middleware.AuditContext())
 
 	return h
 }
@@ -1,36 +1,82 @@
 package utils
 
 import (
+	"errors"
 	"time"
 
+	"backend/internal/core/config"
+
 	"github.com/golang-jwt/jwt/v4"
 )
 
-var JwtSecret = []byte("your_super_secret_key_change_me") // TODO: Move to config
+var (
+	ErrInvalidIssuer   = errors.New("token has invalid issuer")
+	ErrInvalidAudience = errors.New("token has invalid audience")
+	ErrInvalidPurpose  = errors.New("token has invalid purpose")
+)
+
+// Purposes for short-lived tokens that must never be accepted as access tokens
+const (
+	PurposeMFAPending    = "mfa_pending"
+	PurposePasswordReset = "password_reset"
+)
 
 type Claims struct {
-	UserID uint `json:"user_id"`
+	UserID  uint   `json:"user_id"`
+	Role    string `json:"role"`
+	Purpose string `json:"purpose,omitempty"` // Empty for access tokens
+	Binding string `json:"bnd,omitempty"`     // Ties a purpose token to account state so it can't outlive it
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uint) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
-	claims := &Claims{
-		UserID: userID,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-		},
+// GenerateToken issues an access token.
+func GenerateToken(userID uint, role string) (string, error) {
+	return signToken(&Claims{UserID: userID, Role: role}, config.JWT.TTL)
+}
+
+// GeneratePurposeToken issues a token that is only valid for one step of a flow,
+// e.g. completing a login with a second factor. The binding is echoed back by
+// ParsePurposeToken so callers can reject tokens once the bound state has changed.
+func GeneratePurposeToken(userID uint, purpose, binding string, ttl time.Duration) (string, error) {
+	return signToken(&Claims{UserID: userID, Purpose: purpose, Binding: binding}, ttl)
+}
+
+func signToken(claims *Claims, ttl time.Duration) (string, error) {
+	ks, err := currentKeySet()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    config.JWT.Issuer,
+		Audience:  jwt.ClaimStrings{config.JWT.Audience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(JwtSecret)
+	key, kid := ks.signingKey()
+	token := jwt.NewWithClaims(ks.method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	return token.SignedString(key)
 }
 
+// ParseToken validates an access token.
 func ParseToken(tokenString string) (*Claims, error) {
+	return ParsePurposeToken(tokenString, "")
+}
+
+// ParsePurposeToken validates a token issued for the given purpose.
+func ParsePurposeToken(tokenString, purpose string) (*Claims, error) {
+	ks, err := currentKeySet()
+	if err != nil {
+		return nil, err
+	}
+
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return JwtSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, claims, ks.verificationKey, jwt.WithValidMethods([]string{ks.method.Alg()}))
 
 	if err != nil {
 		return nil, err
@@ -40,5 +86,15 @@ func ParseToken(tokenString string) (*Claims, error) {
 		return nil, jwt.ErrSignatureInvalid
 	}
 
+	if !claims.VerifyIssuer(config.JWT.Issuer, true) {
+		return nil, ErrInvalidIssuer
+	}
+	if !claims.VerifyAudience(config.JWT.Audience, true) {
+		return nil, ErrInvalidAudience
+	}
+	if claims.Purpose != purpose {
+		return nil, ErrInvalidPurpose
+	}
+
 	return claims, nil
 }
@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"backend/internal/core/config"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Asymmetric keys are loaded from JWT_KEYS_DIR. Every "<kid>.pem" file holds a private
+// key, every "<kid>.pub.pem" file holds a public key that is only used for verification.
+// JWT_ACTIVE_KID selects the private key new tokens are signed with.
+//
+// Rotating keys:
+//  1. Add the new private key as "<new-kid>.pem" and set JWT_ACTIVE_KID to it.
+//  2. Replace the old private key with its public half ("<old-kid>.pub.pem") so tokens
+//     signed with it keep verifying and stay published in the JWKS.
+//  3. Once JWT_TTL has elapsed, delete the old public key.
+//
+// Example: openssl genpkey -algorithm ed25519 -out keys/2024-01.pem
+
+var ErrUnknownKeyID = errors.New("token signed with unknown key id")
+
+type signingKey struct {
+	kid     string
+	private crypto.PrivateKey // nil for verification-only keys
+	public  crypto.PublicKey
+}
+
+type keySet struct {
+	method     jwt.SigningMethod
+	hmacSecret []byte
+	active     *signingKey
+	keys       map[string]*signingKey
+}
+
+var jwtKeys *keySet
+
+// InitJWT prepares the signing keys for the configured algorithm. It must run after
+// config.Init and before any token is generated or parsed.
+func InitJWT() {
+	ks, err := loadKeySet(config.JWT)
+	if err != nil {
+		log.Fatalf("failed to load JWT keys: %v", err)
+	}
+	jwtKeys = ks
+}
+
+func loadKeySet(cfg config.JWTConfig) (*keySet, error) {
+	method := jwt.GetSigningMethod(cfg.Algorithm)
+	switch method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return &keySet{method: method, hmacSecret: []byte(cfg.Secret)}, nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodEd25519:
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", cfg.Algorithm)
+	}
+
+	ks := &keySet{method: method, keys: map[string]*signingKey{}}
+
+	files, err := filepath.Glob(filepath.Join(cfg.KeysDir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		key, err := readKeyFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if !keyMatchesMethod(key.public, method) {
+			return nil, fmt.Errorf("%s: key type does not match algorithm %s", file, cfg.Algorithm)
+		}
+		if _, exists := ks.keys[key.kid]; exists {
+			return nil, fmt.Errorf("duplicate key id %q", key.kid)
+		}
+		ks.keys[key.kid] = key
+	}
+
+	active, ok := ks.keys[cfg.ActiveKID]
+	if !ok || active.private == nil {
+		return nil, fmt.Errorf("no private key found for JWT_ACTIVE_KID %q in %s", cfg.ActiveKID, cfg.KeysDir)
+	}
+	ks.active = active
+
+	return ks, nil
+}
+
+func readKeyFile(file string) (*signingKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(file)
+	if kid, ok := strings.CutSuffix(name, ".pub.pem"); ok {
+		public, err := parsePublicKey(data)
+		if err != nil {
+			return nil, err
+		}
+		return &signingKey{kid: kid, public: public}, nil
+	}
+
+	private, err := parsePrivateKey(data)
+	if err != nil {
+		return nil, err
+	}
+	return &signingKey{
+		kid:     strings.TrimSuffix(name, ".pem"),
+		private: private,
+		public:  private.(crypto.Signer).Public(),
+	}, nil
+}
+
+func parsePrivateKey(data []byte) (crypto.PrivateKey, error) {
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	return jwt.ParseEdPrivateKeyFromPEM(data)
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	return jwt.ParseEdPublicKeyFromPEM(data)
+}
+
+func keyMatchesMethod(public crypto.PublicKey, method jwt.SigningMethod) bool {
+	switch public.(type) {
+	case *rsa.PublicKey:
+		_, ok := method.(*jwt.SigningMethodRSA)
+		return ok
+	case ed25519.PublicKey:
+		_, ok := method.(*jwt.SigningMethodEd25519)
+		return ok
+	}
+	return false
+}
+
+func currentKeySet() (*keySet, error) {
+	if jwtKeys == nil {
+		return nil, errors.New("JWT keys not initialized")
+	}
+	return jwtKeys, nil
+}
+
+// signingKey returns the key new tokens are signed with and its kid, empty for HMAC.
+func (ks *keySet) signingKey() (interface{}, string) {
+	if ks.active == nil {
+		return ks.hmacSecret, ""
+	}
+	return ks.active.private, ks.active.kid
+}
+
+// verificationKey picks the key matching the token's kid header.
+func (ks *keySet) verificationKey(token *jwt.Token) (interface{}, error) {
+	if ks.active == nil {
+		return ks.hmacSecret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+	return key.public, nil
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKS returns every public key tokens may currently be verified with.
+// It is empty when tokens are signed with a shared HMAC secret.
+func PublicJWKS() (*JWKS, error) {
+	ks, err := currentKeySet()
+	if err != nil {
+		return nil, err
+	}
+
+	set := &JWKS{Keys: []JWK{}}
+	for _, key := range ks.keys {
+		jwk := JWK{Kid: key.kid, Use: "sig", Alg: ks.method.Alg()}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set, nil
+}
@@ -1,13 +1,144 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"backend/internal/core/config"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashes are either bcrypt ("$2a$...") or argon2id in the PHC string format
+// ("$argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>"). The algorithm and parameters for new
+// hashes come from config.PasswordHash; verification reads them from the hash itself.
+
+const (
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+var errInvalidHash = errors.New("invalid password hash")
+
+type argon2Params struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+}
 
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	cfg := passwordHashConfig()
+	if cfg.Algorithm == "argon2id" {
+		return hashArgon2id(password, argon2Params{memory: cfg.Argon2Memory, time: cfg.Argon2Time, threads: cfg.Argon2Threads})
+	}
+
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), cfg.BcryptCost)
 	return string(bytes), err
 }
 
 func CheckPasswordHash(password, hash string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return false
+		}
+		other := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+		return subtle.ConstantTimeCompare(key, other) == 1
+	}
+
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// CheckDummyPasswordHash costs as much as checking a password against a real hash, for
+// logins with an unknown account. Without it, response times would reveal which
+// accounts exist. It always returns false.
+func CheckDummyPasswordHash(password string) bool {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = HashPassword(rand.Text())
+	})
+	CheckPasswordHash(password, dummyHash)
+	return false
+}
+
+// PasswordNeedsRehash reports whether hash was made with a different algorithm or
+// parameters than HashPassword currently uses.
+func PasswordNeedsRehash(hash string) bool {
+	cfg := passwordHashConfig()
+
+	if cfg.Algorithm == "argon2id" {
+		params, _, _, err := decodeArgon2id(hash)
+		if err != nil {
+			return true
+		}
+		return params != argon2Params{memory: cfg.Argon2Memory, time: cfg.Argon2Time, threads: cfg.Argon2Threads}
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != cfg.BcryptCost
+}
+
+// passwordHashConfig falls back to sane defaults when config.Init hasn't run, e.g. in tools.
+func passwordHashConfig() config.PasswordHashConfig {
+	cfg := config.PasswordHash
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = "bcrypt"
+	}
+	if cfg.BcryptCost == 0 {
+		cfg.BcryptCost = bcrypt.DefaultCost
+	}
+	return cfg
+}
+
+func hashArgon2id(password string, params argon2Params) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, argon2KeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.memory, params.time, params.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func decodeArgon2id(hash string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, errInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errInvalidHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return params, nil, nil, errInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, errInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errInvalidHash
+	}
+	return params, salt, key, nil
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slugify turns a name into a URL-friendly slug: lowercase letters and digits separated
+// by single hyphens, e.g. "Go & Postgres" becomes "go-postgres". It returns "" when the
+// name has no letters or digits.
+func Slugify(name string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(r)
+			continue
+		}
+		pendingHyphen = true
+	}
+	return b.String()
+}
@@ -0,0 +1,14 @@
+package utils
+
+// Truncate cuts s to at most n characters, so client-supplied values fit the varchar
+// columns they are stored in. Postgres counts characters, not bytes.
+func Truncate(s string, n int) string {
+	count := 0
+	for i := range s {
+		if count == n {
+			return s[:i]
+		}
+		count++
+	}
+	return s
+}
@@ -0,0 +1,58 @@
+package validation
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+const (
+	usernameMinLength = 3
+	usernameMaxLength = 32
+	emailMaxLength    = 254 // The longest address SMTP can deliver to
+)
+
+// Letters, digits, dots, dashes and underscores, starting with a letter or digit
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// Username returns what is wrong with a username, or "" if it is acceptable.
+func Username(username string) string {
+	switch {
+	case username == "":
+		return "is required"
+	case len(username) < usernameMinLength || len(username) > usernameMaxLength:
+		return "must be between 3 and 32 characters"
+	case !usernamePattern.MatchString(username):
+		return "may only contain letters, digits, '.', '-' and '_', and must start with a letter or digit"
+	}
+	return ""
+}
+
+// Email returns what is wrong with an email address, or "" if it is acceptable. Only
+// bare addresses are accepted, not "Name <address>" forms.
+func Email(email string) string {
+	if email == "" {
+		return "is required"
+	}
+	if len(email) > emailMaxLength {
+		return "must be at most 254 characters"
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || addr.Name != "" {
+		return "must be a valid email address"
+	}
+	_, domain, _ := strings.Cut(email, "@")
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "must be a valid email address"
+	}
+	return ""
+}
+
+// Account validates the fields of a new account.
+func Account(username, email, password string) Errors {
+	errs := Errors{}
+	errs.Add("username", Username(username))
+	errs.Add("email", Email(email))
+	errs.Add("password", Password(password, username, email))
+	return errs
+}
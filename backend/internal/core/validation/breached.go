@@ -0,0 +1,83 @@
+package validation
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"backend/internal/core/config"
+)
+
+// Breached passwords are looked up offline in the k-anonymity range format used by
+// Pwned Passwords: the SHA-1 hash is split into a 5 character prefix and the remaining
+// suffix, and PASSWORD_BREACHED_DIR/<PREFIX>.txt lists "SUFFIX:COUNT" lines. This is the
+// layout the official haveibeenpwned downloader produces. A short list of the most
+// common passwords is bundled and always checked.
+
+//go:embed common_passwords.sha1
+var bundledHashList string
+
+var (
+	bundledOnce   sync.Once
+	bundledHashes map[string]struct{}
+)
+
+func isBreached(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	bundledOnce.Do(loadBundled)
+	if _, ok := bundledHashes[hash]; ok {
+		return true
+	}
+
+	dir := config.PasswordPolicy.BreachedDir
+	if dir == "" {
+		return false
+	}
+	found, err := inRangeFile(filepath.Join(dir, hash[:5]+".txt"), hash[5:])
+	if err != nil {
+		// Fail open, a broken hash list shouldn't lock everyone out of setting passwords
+		log.Printf("breached password check failed: %v", err)
+		return false
+	}
+	return found
+}
+
+func loadBundled() {
+	bundledHashes = map[string]struct{}{}
+	for _, line := range strings.Split(bundledHashList, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			bundledHashes[line] = struct{}{}
+		}
+	}
+}
+
+func inRangeFile(path, suffix string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil // No breached password shares this prefix
+		}
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		candidate, _, _ := strings.Cut(scanner.Text(), ":")
+		if strings.EqualFold(strings.TrimSpace(candidate), suffix) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,50 @@
+// Package validation checks user-supplied account data and reports problems per field.
+package validation
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Errors maps field names to what is wrong with them.
+type Errors map[string]string
+
+func (e Errors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field, msg := range e {
+		fields = append(fields, field+" "+msg)
+	}
+	sort.Strings(fields)
+	return "validation failed: " + strings.Join(fields, "; ")
+}
+
+// Add records the first problem found for a field.
+func (e Errors) Add(field, msg string) {
+	if _, exists := e[field]; !exists && msg != "" {
+		e[field] = msg
+	}
+}
+
+// Err returns nil when no problems were recorded, so callers can `return errs.Err()`.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Response is the JSON body handlers send for validation failures.
+func (e Errors) Response() map[string]interface{} {
+	return map[string]interface{}{
+		"error":  "validation failed",
+		"fields": e,
+	}
+}
+
+// As extracts field errors from err, if it carries any.
+func As(err error) (Errors, bool) {
+	var errs Errors
+	ok := errors.As(err, &errs)
+	return errs, ok
+}
@@ -0,0 +1,79 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"backend/internal/core/config"
+)
+
+const (
+	defaultPasswordMinLength = 10
+	bcryptMaxBytes           = 72 // bcrypt ignores or rejects anything longer
+	passwordMaxBytes         = 256
+)
+
+// Password returns what is wrong with a password, or "" if it is acceptable. related
+// holds values the password must not contain, like the username and email.
+func Password(password string, related ...string) string {
+	policy := config.PasswordPolicy
+	if policy.MinLength == 0 {
+		policy.MinLength = defaultPasswordMinLength
+	}
+
+	if password == "" {
+		return "is required"
+	}
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		return fmt.Sprintf("must be at least %d characters", policy.MinLength)
+	}
+	maxBytes := passwordMaxBytes
+	if config.PasswordHash.Algorithm != "argon2id" {
+		maxBytes = bcryptMaxBytes
+	}
+	if len(password) > maxBytes {
+		return fmt.Sprintf("must be at most %d bytes", maxBytes)
+	}
+	if characterClasses(password) < policy.MinCharClasses {
+		return fmt.Sprintf("must contain at least %d of: lowercase letters, uppercase letters, digits, symbols", policy.MinCharClasses)
+	}
+
+	lower := strings.ToLower(password)
+	for _, value := range related {
+		value, _, _ = strings.Cut(strings.ToLower(value), "@") // Only the local part of an email
+		if len(value) >= 3 && strings.Contains(lower, value) {
+			return "must not contain your username or email"
+		}
+	}
+
+	if policy.CheckBreached && isBreached(password) {
+		return "has appeared in a data breach, choose a different password"
+	}
+	return ""
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	count := 0
+	for _, present := range []bool{lower, upper, digit, other} {
+		if present {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Entry is one row of the audit log. Rows are never updated or deleted, the table
+// rejects both at the database level.
+type Entry struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time       `gorm:"index" json:"created_at"`
+	ActorID    *uint           `gorm:"index" json:"actor_id"` // Nil for anonymous actions, e.g. a failed login
+	Action     string          `gorm:"index;not null;size:64" json:"action"`
+	TargetType string          `gorm:"index:idx_audit_target;size:32" json:"target_type"`
+	TargetID   string          `gorm:"index:idx_audit_target;size:64" json:"target_id"`
+	IP         string          `gorm:"size:64" json:"ip"`
+	UserAgent  string          `gorm:"size:512" json:"user_agent"`
+	Changes    json.RawMessage `gorm:"type:jsonb" json:"changes,omitempty"` // Field name to {before, after}
+}
+
+func (Entry) TableName() string {
+	return "audit_logs"
+}
+
+// Filter holds the audit log filters listing.Params can't express. Zero values match
+// everything.
+type Filter struct {
+	ActorID *uint
+	Action  string // Exact action, or a prefix ending in "." such as "auth."
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"backend/internal/core/audit"
+	"backend/internal/core/listing"
+	"backend/internal/core/validation"
+	"backend/internal/modules/audit/domain"
+	"backend/internal/modules/audit/repository"
+	"backend/internal/modules/audit/service"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// The log grows without bound, so a listing without ?limit= gets the newest entries only
+var listSpec = listing.Spec{
+	Sorts:       []string{"created_at"},
+	DefaultSort: "-created_at",
+	Filters: []listing.Filter{
+		{Param: "target_type", Column: "target_type"},
+		{Param: "target_id", Column: "target_id"},
+	},
+	DateColumn:   "created_at",
+	DefaultLimit: 50,
+}
+
+type AuditHandler struct {
+	svc *service.AuditService
+}
+
+func NewAuditHandler() *AuditHandler {
+	repo := repository.NewPostgresAuditRepository()
+	svc := service.NewAuditService(repo)
+	return &AuditHandler{svc: svc}
+}
+
+// Recorder is installed with audit.Use so the other modules' events end up in the log.
+func (h *AuditHandler) Recorder() audit.Recorder {
+	return h.svc
+}
+
+// GetEntries lists the audit log, newest first, with the listing parameters (50 entries
+// unless ?limit= says otherwise). Besides target_type and target_id it filters by
+// actor_id and action, either exact or a prefix like "auth.".
+func (h *AuditHandler) GetEntries(c context.Context, ctx *app.RequestContext) {
+	params, err := listing.FromRequest(ctx, listSpec)
+	if errs, ok := validation.As(err); ok {
+		ctx.JSON(http.StatusBadRequest, errs.Response())
+		return
+	}
+
+	filter := domain.Filter{Action: ctx.Query("action")}
+	if raw := ctx.Query("actor_id"); raw != "" {
+		actorID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, validation.Errors{"actor_id": "must be a user ID"}.Response())
+			return
+		}
+		id := uint(actorID)
+		filter.ActorID = &id
+	}
+
+	entries, total, err := h.svc.GetEntries(c, filter, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	listing.SetHeaders(ctx, params, total)
+	ctx.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,13 @@
+package port
+
+import (
+	"backend/internal/core/listing"
+	"backend/internal/modules/audit/domain"
+	"context"
+)
+
+type AuditRepository interface {
+	Create(ctx context.Context, entry *domain.Entry) error
+	// FindAll returns a page of entries and the total number of matches.
+	FindAll(ctx context.Context, filter domain.Filter, params listing.Params) ([]domain.Entry, int64, error)
+}
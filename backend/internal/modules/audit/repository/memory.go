@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"backend/internal/core/listing"
+	"backend/internal/modules/audit/domain"
+	"backend/internal/modules/audit/port"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryAuditRepository keeps the audit log in a slice, for tests and tools that run
+// without a database. It is append-only like the table.
+type MemoryAuditRepository struct {
+	mu      sync.RWMutex
+	entries []domain.Entry
+}
+
+var _ port.AuditRepository = (*MemoryAuditRepository)(nil)
+
+func NewMemoryAuditRepository() *MemoryAuditRepository {
+	return &MemoryAuditRepository{}
+}
+
+func (r *MemoryAuditRepository) Create(ctx context.Context, entry *domain.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry.ID = uint(len(r.entries) + 1)
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+func (r *MemoryAuditRepository) FindAll(ctx context.Context, filter domain.Filter, params listing.Params) ([]domain.Entry, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []domain.Entry
+	for _, entry := range r.entries {
+		if matchesFilter(entry, filter) {
+			matched = append(matched, entry)
+		}
+	}
+	page, total := listing.Slice(matched, params)
+	return page, total, nil
+}
+
+func matchesFilter(entry domain.Entry, filter domain.Filter) bool {
+	if filter.ActorID != nil && (entry.ActorID == nil || *entry.ActorID != *filter.ActorID) {
+		return false
+	}
+	if filter.Action != "" {
+		if strings.HasSuffix(filter.Action, ".") {
+			if !strings.HasPrefix(entry.Action, filter.Action) {
+				return false
+			}
+		} else if entry.Action != filter.Action {
+			return false
+		}
+	}
+	return true
+}
+
+// Snapshot implements db.Snapshotter, so a MemoryTransactor can undo writes made in a
+// transaction that fails.
+func (r *MemoryAuditRepository) Snapshot() func() {
+	r.mu.RLock()
+	entries := slices.Clone(r.entries)
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		r.entries = entries
+		r.mu.Unlock()
+	}
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"backend/internal/core/db"
+	"backend/internal/core/listing"
+	"backend/internal/modules/audit/domain"
+	"backend/internal/modules/audit/port"
+	"context"
+	"strings"
+)
+
+type PostgresAuditRepository struct{}
+
+var _ port.AuditRepository = (*PostgresAuditRepository)(nil)
+
+func NewPostgresAuditRepository() *PostgresAuditRepository {
+	return &PostgresAuditRepository{}
+}
+
+func (r *PostgresAuditRepository) Create(ctx context.Context, entry *domain.Entry) error {
+	return db.Conn(ctx).Create(entry).Error
+}
+
+func (r *PostgresAuditRepository) FindAll(ctx context.Context, filter domain.Filter, params listing.Params) ([]domain.Entry, int64, error) {
+	query := db.Conn(ctx)
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		if strings.HasSuffix(filter.Action, ".") {
+			query = query.Where("action LIKE ?", filter.Action+"%")
+		} else {
+			query = query.Where("action = ?", filter.Action)
+		}
+	}
+
+	var entries []domain.Entry
+	total, err := listing.Find(query, params, &entries)
+	return entries, total, err
+}
+
+// EnsureAppendOnly installs a trigger that rejects UPDATE and DELETE on the audit log,
+// so entries can't be rewritten through the application or a stray query.
+func (r *PostgresAuditRepository) EnsureAppendOnly(ctx context.Context) error {
+	return db.Conn(ctx).Exec(`
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs;
+CREATE TRIGGER audit_logs_append_only
+	BEFORE UPDATE OR DELETE ON audit_logs
+	FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+`).Error
+}
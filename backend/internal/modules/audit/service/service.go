@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"backend/internal/core/audit"
+	"backend/internal/core/listing"
+	"backend/internal/core/utils"
+	"backend/internal/modules/audit/domain"
+	"backend/internal/modules/audit/port"
+)
+
+type AuditService struct {
+	repo port.AuditRepository
+}
+
+var _ audit.Recorder = (*AuditService)(nil)
+
+func NewAuditService(repo port.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+func (s *AuditService) Record(ctx context.Context, event audit.Event, client audit.Client) error {
+	entry := &domain.Entry{
+		ActorID:    event.ActorID,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		IP:         utils.Truncate(client.IP, 64),
+		UserAgent:  utils.Truncate(client.UserAgent, 512),
+	}
+	if len(event.Changes) > 0 {
+		changes, err := json.Marshal(event.Changes)
+		if err != nil {
+			return err
+		}
+		entry.Changes = changes
+	}
+
+	// Record even if the request was cancelled right after the audited action
+	return s.repo.Create(context.WithoutCancel(ctx), entry)
+}
+
+func (s *AuditService) GetEntries(ctx context.Context, filter domain.Filter, params listing.Params) ([]domain.Entry, int64, error) {
+	return s.repo.FindAll(ctx, filter, params)
+}
@@ -2,32 +2,53 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
 
+	"backend/internal/core/config"
+	"backend/internal/core/mailer"
+	"backend/internal/core/rbac"
+	"backend/internal/core/server/middleware"
+	"backend/internal/core/utils"
+	"backend/internal/core/validation"
 	"backend/internal/modules/auth/service"
+	sysRepo "backend/internal/modules/system/repository"
 	userRepo "backend/internal/modules/user/repository"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"gorm.io/gorm"
 )
 
 type AuthHandler struct {
-	svc *service.AuthService
+	svc  *service.AuthService
+	oidc *service.OIDCService
 }
 
 func NewAuthHandler() *AuthHandler {
 	// Dependency Injection (Manual for now)
 	repo := userRepo.NewPostgresUserRepository()
-	svc := service.NewAuthService(repo)
+	refreshRepo := userRepo.NewPostgresRefreshTokenRepository()
+	inviteRepo := userRepo.NewPostgresInviteRepository()
+	attemptRepo := userRepo.NewPostgresLoginAttemptRepository()
+	apiTokenRepo := userRepo.NewPostgresAPITokenRepository()
+	systemRepo := sysRepo.NewCachedSystemRepository()
+	identityRepo := userRepo.NewPostgresIdentityRepository()
+	svc := service.NewAuthService(repo, refreshRepo, inviteRepo, attemptRepo, apiTokenRepo, systemRepo, mailer.Default)
 
 	return &AuthHandler{
-		svc: svc,
+		svc:  svc,
+		oidc: service.NewOIDCService(svc, identityRepo, config.OIDC),
 	}
 }
 
 type RegisterRequest struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Username    string `json:"username"`
+	Email       string `json:"email"`
+	Password    string `json:"password"`
+	InviteToken string `json:"invite_token"`
 }
 
 type LoginRequest struct {
@@ -35,6 +56,18 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type CreateInviteRequest struct {
+	Email          string `json:"email"`
+	Role           string `json:"role"`
+	ExpiresInHours int    `json:"expires_in_hours"`
+}
+
+const defaultInviteTTL = 72 * time.Hour
+
 func (h *AuthHandler) Register(c context.Context, ctx *app.RequestContext) {
 	var req RegisterRequest
 	if err := ctx.BindAndValidate(&req); err != nil {
@@ -42,7 +75,15 @@ func (h *AuthHandler) Register(c context.Context, ctx *app.RequestContext) {
 		return
 	}
 
-	if err := h.svc.Register(c, req.Username, req.Email, req.Password); err != nil {
+	if err := h.svc.Register(c, req.Username, req.Email, req.Password, req.InviteToken); err != nil {
+		if errs, ok := validation.As(err); ok {
+			ctx.JSON(consts.StatusBadRequest, errs.Response())
+			return
+		}
+		if errors.Is(err, service.ErrRegistrationClosed) || errors.Is(err, service.ErrInvalidInvite) {
+			ctx.JSON(consts.StatusForbidden, map[string]string{"error": err.Error()})
+			return
+		}
 		ctx.JSON(consts.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
 	}
@@ -57,11 +98,391 @@ func (h *AuthHandler) Login(c context.Context, ctx *app.RequestContext) {
 		return
 	}
 
-	token, err := h.svc.Login(c, req.Email, req.Password)
+	result, err := h.svc.Login(c, req.Email, req.Password, clientInfo(ctx))
+	if err != nil {
+		if writeThrottled(ctx, err) {
+			return
+		}
+		if errors.Is(err, service.ErrAccountDisabled) {
+			ctx.JSON(consts.StatusForbidden, map[string]string{"error": err.Error()})
+			return
+		}
+		ctx.JSON(consts.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(consts.StatusOK, result)
+}
+
+func clientInfo(ctx *app.RequestContext) service.ClientInfo {
+	return service.ClientInfo{IP: ctx.ClientIP(), UserAgent: string(ctx.UserAgent())}
+}
+
+// writeThrottled responds with 429 (backoff) or 423 (lockout) and a Retry-After header
+// if err is a throttling error.
+func writeThrottled(ctx *app.RequestContext, err error) bool {
+	var throttled *service.ThrottledError
+	if !errors.As(err, &throttled) {
+		return false
+	}
+
+	status := consts.StatusTooManyRequests
+	if throttled.Locked {
+		status = consts.StatusLocked
+	}
+	ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	ctx.JSON(status, map[string]string{"error": err.Error()})
+	return true
+}
+
+func (h *AuthHandler) Refresh(c context.Context, ctx *app.RequestContext) {
+	var req RefreshRequest
+	if err := ctx.BindAndValidate(&req); err != nil || req.RefreshToken == "" {
+		ctx.JSON(consts.StatusBadRequest, map[string]string{"error": "refresh_token is required"})
+		return
+	}
+
+	tokens, err := h.svc.Refresh(c, req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) || errors.Is(err, service.ErrRefreshTokenReused) || errors.Is(err, service.ErrAccountDisabled) {
+			ctx.JSON(consts.StatusUnauthorized, map[string]string{"error": err.Error()})
+			return
+		}
+		ctx.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(consts.StatusOK, tokens)
+}
+
+func (h *AuthHandler) Logout(c context.Context, ctx *app.RequestContext) {
+	var req RefreshRequest
+	if err := ctx.BindAndValidate(&req); err != nil || req.RefreshToken == "" {
+		ctx.JSON(consts.StatusBadRequest, map[string]string{"error": "refresh_token is required"})
+		return
+	}
+
+	if err := h.svc.Logout(c, req.RefreshToken); err != nil {
+		ctx.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(consts.StatusOK, map[string]string{"message": "Logged out"})
+}
+
+func (h *AuthHandler) GetInvites(c context.Context, ctx *app.RequestContext) {
+	invites, err := h.svc.GetAllInvites(c)
+	if err != nil {
+		ctx.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	ctx.JSON(consts.StatusOK, invites)
+}
+
+func (h *AuthHandler) CreateInvite(c context.Context, ctx *app.RequestContext) {
+	var req CreateInviteRequest
+	if err := ctx.BindAndValidate(&req); err != nil {
+		ctx.JSON(consts.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ttl := defaultInviteTTL
+	if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+
+	if req.Role == "" {
+		req.Role = rbac.RoleViewer
+	}
+
+	token, invite, err := h.svc.CreateInvite(c, ctx.GetUint(middleware.ContextUserID), req.Email, req.Role, ttl)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRole) {
+			ctx.JSON(consts.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		ctx.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// The raw token is only shown once
+	ctx.JSON(consts.StatusCreated, map[string]interface{}{
+		"token":  token,
+		"invite": invite,
+	})
+}
+
+func (h *AuthHandler) DeleteInvite(c context.Context, ctx *app.RequestContext) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		ctx.JSON(consts.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return
+	}
+	if err := h.svc.DeleteInvite(c, uint(id)); err != nil {
+		ctx.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	ctx.JSON(consts.StatusOK, map[string]string{"message": "Invite deleted"})
+}
+
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"` // 0 never expires
+}
+
+// APITokenValidator exposes the service to the auth middleware so it can accept
+// personal access tokens.
+func (h *AuthHandler) APITokenValidator() middleware.APITokenValidator {
+	return h.svc
+}
+
+func (h *AuthHandler) GetAPITokens(c context.Context, ctx *app.RequestContext) {
+	tokens, err := h.svc.GetAPITokens(c, ctx.GetUint(middleware.ContextUserID))
+	if err != nil {
+		ctx.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	ctx.JSON(consts.StatusOK, tokens)
+}
+
+func (h *AuthHandler) CreateAPIToken(c context.Context, ctx *app.RequestContext) {
+	var req CreateAPITokenRequest
+	if err := ctx.BindAndValidate(&req); err != nil {
+		ctx.JSON(consts.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if req.Name == "" {
+		ctx.JSON(consts.StatusBadRequest, map[string]string{"error": "name is required"})
+		return
+	}
+
+	ttl := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	raw, token, err := h.svc.CreateAPIToken(c, ctx.GetUint(middleware.ContextUserID), req.Name, req.Scopes, ttl)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidScopes) {
+			ctx.JSON(consts.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		ctx.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// The raw token is only shown once
+	ctx.JSON(consts.StatusCreated, map[string]interface{}{
+		"token":     raw,
+		"api_token": token,
+	})
+}
+
+func (h *AuthHandler) RevokeAPIToken(c context.Context, ctx *app.RequestContext) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		ctx.JSON(consts.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return
+	}
+	if err := h.svc.RevokeAPIToken(c, ctx.GetUint(middleware.ContextUserID), uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(consts.StatusNotFound, map[string]string{"error": "API token not found"})
+			return
+		}
+		ctx.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	ctx.JSON(consts.StatusOK, map[string]string{"message": "API token revoked"})
+}
+
+// JWKS publishes the public keys tokens are signed with so other services can verify
+// them without sharing a secret.
+func (h *AuthHandler) JWKS(c context.Context, ctx *app.RequestContext) {
+	jwks, err := utils.PublicJWKS()
+	if err != nil {
+		ctx.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(consts.StatusOK, jwks)
+}
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"` // TOTP code or recovery code
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type TOTPDisableRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+func (h *AuthHandler) VerifyMFA(c context.Context, ctx *app.RequestContext) {
+	var req MFAVerifyRequest
+	if err := ctx.BindAndValidate(&req); err != nil || req.MFAToken == "" || req.Code == "" {
+		ctx.JSON(consts.StatusBadRequest, map[string]string{"error": "mfa_token and code are required"})
+		return
+	}
+
+	tokens, err := h.svc.CompleteMFALogin(c, req.MFAToken, req.Code, clientInfo(ctx))
+	if err != nil {
+		if writeThrottled(ctx, err) {
+			return
+		}
+		if errors.Is(err, service.ErrAccountDisabled) {
+			ctx.JSON(consts.StatusForbidden, map[string]string{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidMFAToken) || errors.Is(err, service.ErrInvalidMFACode) {
+			ctx.JSON(consts.StatusUnauthorized, map[string]string{"error": err.Error()})
+			return
+		}
+		ctx.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(consts.StatusOK, tokens)
+}
+
+func (h *AuthHandler) SetupTOTP(c context.Context, ctx *app.RequestContext) {
+	enrollment, err := h.svc.BeginTOTPEnrollment(c, ctx.GetUint(middleware.ContextUserID))
+	if err != nil {
+		writeMFAError(ctx, err)
+		return
+	}
+	ctx.JSON(consts.StatusOK, enrollment)
+}
+
+func (h *AuthHandler) ConfirmTOTP(c context.Context, ctx *app.RequestContext) {
+	var req TOTPCodeRequest
+	if err := ctx.BindAndValidate(&req); err != nil {
+		ctx.JSON(consts.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.svc.ConfirmTOTPEnrollment(c, ctx.GetUint(middleware.ContextUserID), req.Code)
 	if err != nil {
+		writeMFAError(ctx, err)
+		return
+	}
+
+	// Recovery codes are only shown once
+	ctx.JSON(consts.StatusOK, map[string]interface{}{"recovery_codes": codes})
+}
+
+func (h *AuthHandler) DisableTOTP(c context.Context, ctx *app.RequestContext) {
+	var req TOTPDisableRequest
+	if err := ctx.BindAndValidate(&req); err != nil {
+		ctx.JSON(consts.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := h.svc.DisableTOTP(c, ctx.GetUint(middleware.ContextUserID), req.Password, req.Code, clientInfo(ctx)); err != nil {
+		writeMFAError(ctx, err)
+		return
+	}
+	ctx.JSON(consts.StatusOK, map[string]string{"message": "Two-factor authentication disabled"})
+}
+
+func writeMFAError(ctx *app.RequestContext, err error) {
+	if writeThrottled(ctx, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrInvalidMFACode), errors.Is(err, service.ErrInvalidCredentials):
 		ctx.JSON(consts.StatusUnauthorized, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrMFAAlreadyEnabled), errors.Is(err, service.ErrMFANotStarted), errors.Is(err, service.ErrMFANotEnabled):
+		ctx.JSON(consts.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		ctx.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+func (h *AuthHandler) ForgotPassword(c context.Context, ctx *app.RequestContext) {
+	var req ForgotPasswordRequest
+	if err := ctx.BindAndValidate(&req); err != nil || req.Email == "" {
+		ctx.JSON(consts.StatusBadRequest, map[string]string{"error": "email is required"})
+		return
+	}
+
+	if err := h.svc.ForgotPassword(c, req.Email, clientInfo(ctx)); err != nil {
+		if writeThrottled(ctx, err) {
+			return
+		}
+		ctx.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Same response whether or not the account exists
+	ctx.JSON(consts.StatusOK, map[string]string{"message": "If the account exists, a reset link has been sent"})
+}
+
+func (h *AuthHandler) ResetPassword(c context.Context, ctx *app.RequestContext) {
+	var req ResetPasswordRequest
+	if err := ctx.BindAndValidate(&req); err != nil {
+		ctx.JSON(consts.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := h.svc.ResetPassword(c, req.Token, req.Password); err != nil {
+		if errs, ok := validation.As(err); ok {
+			ctx.JSON(consts.StatusBadRequest, errs.Response())
+			return
+		}
+		if errors.Is(err, service.ErrInvalidResetToken) {
+			ctx.JSON(consts.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		ctx.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(consts.StatusOK, map[string]string{"message": "Password has been reset"})
+}
+
+func (h *AuthHandler) ChangePassword(c context.Context, ctx *app.RequestContext) {
+	var req ChangePasswordRequest
+	if err := ctx.BindAndValidate(&req); err != nil {
+		ctx.JSON(consts.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	tokens, err := h.svc.ChangePassword(c, ctx.GetUint(middleware.ContextUserID), req.CurrentPassword, req.NewPassword, clientInfo(ctx))
+	if err != nil {
+		if errs, ok := validation.As(err); ok {
+			// The service validates "password", which this request calls new_password
+			ctx.JSON(consts.StatusBadRequest, validation.Errors{"new_password": errs["password"]}.Response())
+			return
+		}
+		if writeThrottled(ctx, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			ctx.JSON(consts.StatusUnauthorized, map[string]string{"error": "current password is incorrect"})
+		default:
+			ctx.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
 		return
 	}
 
-	ctx.JSON(consts.StatusOK, map[string]string{"token": token})
+	ctx.JSON(consts.StatusOK, tokens)
 }
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"strconv"
+
+	"backend/internal/core/config"
+	"backend/internal/modules/auth/service"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// oidcStateCookie ties the callback to the browser that started the login, so a
+// callback URL can't be used to sign someone else in.
+const (
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/api/auth/oidc"
+)
+
+// OIDCLogin redirects the browser to the identity provider.
+func (h *AuthHandler) OIDCLogin(c context.Context, ctx *app.RequestContext) {
+	authURL, state, err := h.oidc.BeginLogin(c)
+	if err != nil {
+		if errors.Is(err, service.ErrOIDCDisabled) {
+			ctx.JSON(consts.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+		log.Printf("oidc: %v", err)
+		ctx.JSON(consts.StatusBadGateway, map[string]string{"error": "identity provider is unavailable"})
+		return
+	}
+
+	ctx.SetCookie(oidcStateCookie, state, 600, oidcCookiePath, "", protocol.CookieSameSiteLaxMode, config.IsProd(), true)
+	ctx.Redirect(consts.StatusFound, []byte(authURL))
+}
+
+// OIDCCallback finishes the login and sends the browser back to the frontend with the
+// result in the URL fragment, which is never sent to servers or written to access logs.
+func (h *AuthHandler) OIDCCallback(c context.Context, ctx *app.RequestContext) {
+	state := ctx.Query("state")
+	cookie := string(ctx.Cookie(oidcStateCookie))
+	ctx.SetCookie(oidcStateCookie, "", -1, oidcCookiePath, "", protocol.CookieSameSiteLaxMode, config.IsProd(), true)
+
+	result := url.Values{}
+	switch {
+	case ctx.Query("error") != "":
+		result.Set("error", ctx.Query("error"))
+	case state == "" || state != cookie:
+		result.Set("error", service.ErrInvalidOIDCState.Error())
+	default:
+		login, err := h.oidc.CompleteLogin(c, state, ctx.Query("code"), clientInfo(ctx))
+		switch {
+		case err != nil:
+			result.Set("error", err.Error())
+		case login.MFARequired:
+			result.Set("mfa_required", "true")
+			result.Set("mfa_token", login.MFAToken)
+		default:
+			result.Set("token", login.AccessToken)
+			result.Set("refresh_token", login.RefreshToken)
+			result.Set("expires_in", strconv.FormatInt(login.ExpiresIn, 10))
+		}
+	}
+
+	ctx.Redirect(consts.StatusFound, []byte(config.OIDC.FrontendURL+"#"+result.Encode()))
+}
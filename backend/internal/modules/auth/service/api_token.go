@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"backend/internal/core/audit"
+	"backend/internal/core/rbac"
+	"backend/internal/core/utils"
+	"backend/internal/modules/user/domain"
+
+	"gorm.io/gorm"
+)
+
+// Last-used timestamps are only written this often to avoid a DB write per request
+const apiTokenTouchInterval = time.Minute
+
+var (
+	ErrInvalidAPIToken = errors.New("invalid, expired or revoked API token")
+	ErrInvalidScopes   = errors.New("scopes must be permissions granted to your role")
+)
+
+// CreateAPIToken issues a personal access token limited to the given scopes, which must
+// be permissions of the user's role. The raw token is only returned here.
+func (s *AuthService) CreateAPIToken(ctx context.Context, userID uint, name string, scopes []string, ttl time.Duration) (string, *domain.APIToken, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(scopes) == 0 {
+		return "", nil, ErrInvalidScopes
+	}
+	for _, scope := range scopes {
+		if !rbac.Can(user.Role, rbac.Permission(scope)) {
+			return "", nil, ErrInvalidScopes
+		}
+	}
+
+	random, err := utils.RandomString(32)
+	if err != nil {
+		return "", nil, err
+	}
+	raw := domain.APITokenPrefix + random
+
+	token := &domain.APIToken{
+		UserID:    user.ID,
+		Name:      name,
+		Prefix:    raw[:len(domain.APITokenPrefix)+6],
+		TokenHash: utils.HashToken(raw),
+		Scopes:    scopes,
+	}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := s.apiTokenRepo.Create(ctx, token); err != nil {
+		return "", nil, err
+	}
+
+	audit.Record(ctx, audit.Event{
+		Action:     "api_token.create",
+		TargetType: "api_token",
+		TargetID:   audit.ID(token.ID),
+		Changes:    audit.Diff(nil, token),
+	})
+	return raw, token, nil
+}
+
+func (s *AuthService) GetAPITokens(ctx context.Context, userID uint) ([]domain.APIToken, error) {
+	return s.apiTokenRepo.FindByUser(ctx, userID)
+}
+
+func (s *AuthService) RevokeAPIToken(ctx context.Context, userID, id uint) error {
+	if err := s.apiTokenRepo.Revoke(ctx, userID, id); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{Action: "api_token.revoke", TargetType: "api_token", TargetID: audit.ID(id)})
+	return nil
+}
+
+// IsAPIToken reports whether the bearer credential looks like a personal access token.
+func (s *AuthService) IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, domain.APITokenPrefix)
+}
+
+// ValidateAPIToken resolves a personal access token for the auth middleware.
+func (s *AuthService) ValidateAPIToken(ctx context.Context, raw string) (*rbac.Identity, error) {
+	token, err := s.apiTokenRepo.FindByHash(ctx, utils.HashToken(raw))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIToken
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !token.IsUsable(now) {
+		return nil, ErrInvalidAPIToken
+	}
+
+	user, err := s.userRepo.FindByID(ctx, token.UserID)
+	if err != nil || !user.IsActive {
+		return nil, ErrInvalidAPIToken
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > apiTokenTouchInterval {
+		if err := s.apiTokenRepo.TouchLastUsed(ctx, token.ID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return &rbac.Identity{
+		UserID: user.ID,
+		Role:   user.Role,
+		Scopes: []string(token.Scopes), // Plain slice so RequestContext.GetStringSlice can read it
+	}, nil
+}
@@ -3,56 +3,496 @@ package service
 import (
 	"context"
 	"errors"
+	"log"
+	"strings"
+	"time"
 
+	"backend/internal/core/audit"
+	"backend/internal/core/config"
+	"backend/internal/core/mailer"
+	"backend/internal/core/rbac"
 	"backend/internal/core/utils"
+	"backend/internal/core/validation"
+	systemDomain "backend/internal/modules/system/domain"
+	systemPort "backend/internal/modules/system/port"
 	"backend/internal/modules/user/domain"
 	"backend/internal/modules/user/port"
 
 	"gorm.io/gorm"
 )
 
+var (
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrAccountDisabled     = errors.New("account is deactivated")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session revoked")
+	ErrRegistrationClosed  = errors.New("registration is closed")
+	ErrInvalidInvite       = errors.New("invalid, expired or already used invite")
+	ErrInvalidRole         = errors.New("invalid role")
+)
+
 type AuthService struct {
-	userRepo port.UserRepository
+	userRepo     port.UserRepository
+	refreshRepo  port.RefreshTokenRepository
+	inviteRepo   port.InviteRepository
+	attemptRepo  port.LoginAttemptRepository
+	apiTokenRepo port.APITokenRepository
+	systemRepo   systemPort.SystemRepository
+	mailer       mailer.Mailer
+	throttle     *LoginThrottle
+	// resetThrottle counts password reset requests like failed logins. It is separate
+	// from throttle so that requesting resets can't lock anyone out of logging in.
+	resetThrottle *LoginThrottle
 }
 
-func NewAuthService(userRepo port.UserRepository) *AuthService {
+func NewAuthService(
+	userRepo port.UserRepository,
+	refreshRepo port.RefreshTokenRepository,
+	inviteRepo port.InviteRepository,
+	attemptRepo port.LoginAttemptRepository,
+	apiTokenRepo port.APITokenRepository,
+	systemRepo systemPort.SystemRepository,
+	mailer mailer.Mailer,
+) *AuthService {
 	return &AuthService{
-		userRepo: userRepo,
+		userRepo:      userRepo,
+		refreshRepo:   refreshRepo,
+		inviteRepo:    inviteRepo,
+		attemptRepo:   attemptRepo,
+		apiTokenRepo:  apiTokenRepo,
+		systemRepo:    systemRepo,
+		mailer:        mailer,
+		throttle:      NewLoginThrottle(config.LoginThrottle),
+		resetThrottle: NewLoginThrottle(config.LoginThrottle),
 	}
 }
 
-func (s *AuthService) Register(ctx context.Context, username, email, password string) error {
-	// Check if user exists
-	if _, err := s.userRepo.FindByEmailOrUsername(ctx, email, username); err == nil {
-		return errors.New("username or email already exists")
+// ClientInfo describes the caller of a request for throttling and auditing.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // Access token lifetime in seconds
+}
+
+// RegistrationMode returns the configured self-registration policy, closed by default.
+func (s *AuthService) RegistrationMode(ctx context.Context) string {
+	mode, _ := s.systemRepo.GetConfig(ctx, systemDomain.ConfigKeyRegistrationMode)
+	if !systemDomain.IsValidRegistrationMode(mode) {
+		return systemDomain.RegistrationClosed
 	}
+	return mode
+}
 
-	hashedPassword, err := utils.HashPassword(password)
+// Register is the public self-registration entry point and enforces the registration mode.
+func (s *AuthService) Register(ctx context.Context, username, email, password, inviteToken string) error {
+	var user *domain.User
+	var err error
+	switch s.RegistrationMode(ctx) {
+	case systemDomain.RegistrationOpen:
+		user, err = s.createUser(ctx, username, email, password, rbac.RoleViewer)
+	case systemDomain.RegistrationInvite:
+		user, err = s.registerWithInvite(ctx, username, email, password, inviteToken)
+	default:
+		return ErrRegistrationClosed
+	}
 	if err != nil {
 		return err
 	}
 
+	audit.Record(ctx, audit.Event{
+		ActorID:    &user.ID,
+		Action:     "auth.register",
+		TargetType: "user",
+		TargetID:   audit.ID(user.ID),
+		Changes:    audit.Diff(nil, user),
+	})
+	return nil
+}
+
+func (s *AuthService) registerWithInvite(ctx context.Context, username, email, password, inviteToken string) (*domain.User, error) {
+	if inviteToken == "" {
+		return nil, ErrInvalidInvite
+	}
+
+	invite, err := s.inviteRepo.FindByHash(ctx, utils.HashToken(inviteToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInvite
+		}
+		return nil, err
+	}
+	if !invite.IsUsable(time.Now()) || (invite.Email != "" && !strings.EqualFold(invite.Email, email)) {
+		return nil, ErrInvalidInvite
+	}
+
+	// Claim the invite before creating the user so it can't be redeemed twice concurrently
+	claimed, err := s.inviteRepo.Claim(ctx, invite.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrInvalidInvite
+	}
+
+	user, err := s.createUser(ctx, username, email, password, invite.Role)
+	if err != nil {
+		if releaseErr := s.inviteRepo.Release(ctx, invite.ID); releaseErr != nil {
+			return nil, errors.Join(err, releaseErr)
+		}
+		return nil, err
+	}
+
+	if err := s.inviteRepo.SetUsedBy(ctx, invite.ID, user.ID); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// CreateUser creates an account without checking the registration mode, it is used by
+// first-run setup and by admins managing users. Callers record the audit event, after
+// committing if they run it in a transaction.
+func (s *AuthService) CreateUser(ctx context.Context, username, email, password, role string) (*domain.User, error) {
+	return s.createUser(ctx, username, email, password, role)
+}
+
+func (s *AuthService) createUser(ctx context.Context, username, email, password, role string) (*domain.User, error) {
+	if !rbac.IsValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if err := validation.Account(username, email, password).Err(); err != nil {
+		return nil, err
+	}
+
+	if existing, err := s.userRepo.FindByEmailOrUsername(ctx, email, username); err == nil {
+		return nil, accountTaken(existing, username, email)
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
 	newUser := domain.User{
 		Username: username,
 		Email:    email,
 		Password: hashedPassword,
+		Role:     role,
+	}
+
+	if err := s.userRepo.Create(ctx, &newUser); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			// Lost a race with a concurrent signup, find out which field collided
+			if existing, findErr := s.userRepo.FindByEmailOrUsername(ctx, email, username); findErr == nil {
+				return nil, accountTaken(existing, username, email)
+			}
+			return nil, validation.Errors{"email": "is already taken"}
+		}
+		return nil, err
+	}
+	return &newUser, nil
+}
+
+// accountTaken reports which of username and email the existing user already holds.
+func accountTaken(existing *domain.User, username, email string) error {
+	errs := validation.Errors{}
+	if existing.Username == username {
+		errs.Add("username", "is already taken")
+	}
+	if existing.Email == email {
+		errs.Add("email", "is already taken")
+	}
+	if err := errs.Err(); err != nil {
+		return err
+	}
+	return validation.Errors{"email": "is already taken"}
+}
+
+// CreateInvite issues a single-use invite token. The raw token is only returned here.
+func (s *AuthService) CreateInvite(ctx context.Context, createdByID uint, email, role string, ttl time.Duration) (string, *domain.Invite, error) {
+	if !rbac.IsValidRole(role) || role == rbac.RoleOwner {
+		return "", nil, ErrInvalidRole
+	}
+
+	token, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	invite := &domain.Invite{
+		Email:       email,
+		Role:        role,
+		TokenHash:   hash,
+		CreatedByID: createdByID,
+		ExpiresAt:   time.Now().Add(ttl),
 	}
+	if err := s.inviteRepo.Create(ctx, invite); err != nil {
+		return "", nil, err
+	}
+
+	audit.Record(ctx, audit.Event{
+		Action:     "invite.create",
+		TargetType: "invite",
+		TargetID:   audit.ID(invite.ID),
+		Changes:    audit.Diff(nil, invite),
+	})
+	return token, invite, nil
+}
+
+func (s *AuthService) GetAllInvites(ctx context.Context) ([]domain.Invite, error) {
+	return s.inviteRepo.FindAll(ctx)
+}
 
-	return s.userRepo.Create(ctx, &newUser)
+func (s *AuthService) DeleteInvite(ctx context.Context, id uint) error {
+	if err := s.inviteRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{Action: "invite.delete", TargetType: "invite", TargetID: audit.ID(id)})
+	return nil
 }
 
-func (s *AuthService) Login(ctx context.Context, email, password string) (string, error) {
+// Login checks the password. Accounts with two-factor authentication enabled get a
+// short-lived MFA token instead of a session, to be completed with CompleteMFALogin.
+func (s *AuthService) Login(ctx context.Context, email, password string, client ClientInfo) (*LoginResult, error) {
+	if err := s.throttle.Check(email, client.IP, time.Now()); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.FindByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "", errors.New("invalid credentials")
+			utils.CheckDummyPasswordHash(password)
+			return nil, s.loginFailed(ctx, email, nil, client, "unknown_email", ErrInvalidCredentials)
 		}
-		return "", err
+		return nil, err
 	}
 
 	if !utils.CheckPasswordHash(password, user.Password) {
-		return "", errors.New("invalid credentials")
+		return nil, s.loginFailed(ctx, email, &user.ID, client, "bad_password", ErrInvalidCredentials)
+	}
+
+	if !user.IsActive {
+		return nil, s.loginFailed(ctx, email, &user.ID, client, "deactivated", ErrAccountDisabled)
+	}
+
+	if utils.PasswordNeedsRehash(user.Password) {
+		s.upgradePasswordHash(ctx, user, password)
+	}
+
+	return s.finishLogin(ctx, user)
+}
+
+// upgradePasswordHash re-hashes the password with the current parameters. The plaintext
+// is only available during login, so this is the one place old hashes can be upgraded.
+// Failures are logged, the old hash keeps working.
+func (s *AuthService) upgradePasswordHash(ctx context.Context, user *domain.User, password string) {
+	hashed, err := utils.HashPassword(password)
+	if err != nil {
+		log.Printf("failed to upgrade password hash for user %d: %v", user.ID, err)
+		return
+	}
+
+	previous := user.Password
+	user.Password = hashed
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		user.Password = previous
+		log.Printf("failed to upgrade password hash for user %d: %v", user.ID, err)
+	}
+}
+
+// finishLogin continues a login once the first factor has been verified, either asking
+// for the second factor or starting the session.
+func (s *AuthService) finishLogin(ctx context.Context, user *domain.User) (*LoginResult, error) {
+	if user.TOTPEnabled {
+		mfaToken, err := utils.GeneratePurposeToken(user.ID, utils.PurposeMFAPending, passwordFingerprint(user), mfaPendingTTL)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	// Failures are only cleared once the login is complete, otherwise a known password
+	// could be used to reset the counter while guessing second factor codes.
+	s.throttle.RecordSuccess(user.Email)
+
+	tokens, err := s.startSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	recordLogin(ctx, user)
+	return &LoginResult{TokenPair: tokens}, nil
+}
+
+// startSession issues a token pair for a fully authenticated user.
+func (s *AuthService) startSession(ctx context.Context, user *domain.User) (*TokenPair, error) {
+	familyID, err := utils.RandomString(16)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, stored, err := newRefreshToken(user.ID, familyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refreshRepo.Create(ctx, stored); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokenPair(user, refreshToken)
+}
+
+func recordLogin(ctx context.Context, user *domain.User) {
+	audit.Record(ctx, audit.Event{
+		ActorID:    &user.ID,
+		Action:     "auth.login",
+		TargetType: "user",
+		TargetID:   audit.ID(user.ID),
+	})
+}
+
+// loginFailed records the failure for throttling and auditing and returns the error
+// shown to the caller.
+func (s *AuthService) loginFailed(ctx context.Context, email string, userID *uint, client ClientInfo, reason string, shown error) error {
+	s.throttle.RecordFailure(email, client.IP, time.Now())
+
+	attempt := &domain.LoginAttempt{
+		Email:     utils.Truncate(email, 255),
+		UserID:    userID,
+		IP:        utils.Truncate(client.IP, 64),
+		UserAgent: utils.Truncate(client.UserAgent, 512),
+		Reason:    reason,
+	}
+	if err := s.attemptRepo.Create(ctx, attempt); err != nil {
+		// The caller still gets the same answer; a lost record must not reveal more
+		log.Printf("failed to record login attempt: %v", err)
+	}
+
+	event := audit.Event{Action: "auth.login_failed", TargetType: "user"}
+	if userID != nil {
+		event.TargetID = audit.ID(*userID)
+	}
+	audit.Record(ctx, event)
+
+	return shown
+}
+
+// Refresh exchanges a refresh token for a new token pair. Each refresh token can only be
+// used once; presenting an already rotated token revokes every token in its family.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	current, err := s.refreshRepo.FindByHash(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if current.IsRevoked() {
+		if err := s.refreshRepo.RevokeFamily(ctx, current.FamilyID); err != nil {
+			return nil, err
+		}
+		recordRefreshReuse(ctx, current.UserID)
+		return nil, ErrRefreshTokenReused
+	}
+
+	if current.IsExpired(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// Load the user before rotating so deactivated accounts can't keep their session alive
+	user, err := s.userRepo.FindByID(ctx, current.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrAccountDisabled
+	}
+
+	newToken, replacement, err := newRefreshToken(current.UserID, current.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, err := s.refreshRepo.Rotate(ctx, current, replacement)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// Lost the race against another request using the same token
+		if err := s.refreshRepo.RevokeFamily(ctx, current.FamilyID); err != nil {
+			return nil, err
+		}
+		recordRefreshReuse(ctx, current.UserID)
+		return nil, ErrRefreshTokenReused
+	}
+
+	return s.issueTokenPair(user, newToken)
+}
+
+// recordRefreshReuse logs a revoked token being presented again, which usually means it was stolen.
+func recordRefreshReuse(ctx context.Context, userID uint) {
+	audit.Record(ctx, audit.Event{
+		Action:     "auth.refresh_reused",
+		TargetType: "user",
+		TargetID:   audit.ID(userID),
+	})
+}
+
+// Logout revokes the session the refresh token belongs to. Unknown tokens are ignored
+// so logging out is idempotent.
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	current, err := s.refreshRepo.FindByHash(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if err := s.refreshRepo.RevokeFamily(ctx, current.FamilyID); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{
+		ActorID:    &current.UserID,
+		Action:     "auth.logout",
+		TargetType: "user",
+		TargetID:   audit.ID(current.UserID),
+	})
+	return nil
+}
+
+func (s *AuthService) issueTokenPair(user *domain.User, refreshToken string) (*TokenPair, error) {
+	accessToken, err := utils.GenerateToken(user.ID, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(config.JWT.TTL.Seconds()),
+	}, nil
+}
+
+func newRefreshToken(userID uint, familyID string) (string, *domain.RefreshToken, error) {
+	token, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", nil, err
 	}
 
-	return utils.GenerateToken(user.ID)
+	return token, &domain.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(config.JWT.RefreshTTL),
+	}, nil
 }
@@ -0,0 +1,69 @@
+// Package authtest builds an AuthService on memory repositories for the tests of the
+// modules that depend on it.
+package authtest
+
+import (
+	"testing"
+	"time"
+
+	"backend/internal/core/config"
+	"backend/internal/core/utils"
+	"backend/internal/modules/auth/service"
+	systemPort "backend/internal/modules/system/port"
+	systemRepository "backend/internal/modules/system/repository"
+	"backend/internal/modules/user/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password meets the password policy, for accounts created in tests.
+const Password = "Correct-Horse-42-battery"
+
+// Fixture is an AuthService together with the repositories tests need to reach.
+type Fixture struct {
+	Auth          *service.AuthService
+	Users         *repository.MemoryUserRepository
+	RefreshTokens *repository.MemoryRefreshTokenRepository
+}
+
+// New returns an AuthService on memory repositories. system replaces the memory system
+// repository when not nil. It configures the test settings with Configure.
+func New(t testing.TB, system systemPort.SystemRepository) *Fixture {
+	t.Helper()
+	Configure(t)
+
+	if system == nil {
+		system = systemRepository.NewMemorySystemRepository()
+	}
+	users := repository.NewMemoryUserRepository()
+	refreshTokens := repository.NewMemoryRefreshTokenRepository()
+	auth := service.NewAuthService(
+		users,
+		refreshTokens,
+		repository.NewMemoryInviteRepository(),
+		repository.NewMemoryLoginAttemptRepository(),
+		repository.NewMemoryAPITokenRepository(),
+		system,
+		nil,
+	)
+	return &Fixture{Auth: auth, Users: users, RefreshTokens: refreshTokens}
+}
+
+// Configure sets fast password hashing, an HS256 token key and a throttle that never
+// locks out, and puts the previous settings back when t ends.
+func Configure(t testing.TB) {
+	t.Helper()
+
+	passwordHash, jwt, throttle := config.PasswordHash, config.JWT, config.LoginThrottle
+	t.Cleanup(func() {
+		config.PasswordHash, config.JWT, config.LoginThrottle = passwordHash, jwt, throttle
+		if config.JWT.Algorithm != "" {
+			utils.InitJWT()
+		}
+	})
+
+	config.PasswordHash.BcryptCost = bcrypt.MinCost
+	config.JWT = config.JWTConfig{Secret: "test", Issuer: "test", Audience: "test", TTL: time.Minute, RefreshTTL: time.Hour, Algorithm: "HS256"}
+	config.LoginThrottle = config.LoginThrottleConfig{BackoffAfter: 100, MaxAccountFailures: 100, MaxIPFailures: 100, LockoutDuration: time.Minute, Window: time.Minute}
+	utils.InitJWT()
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"backend/internal/core/audit"
+	"backend/internal/core/utils"
+	systemDomain "backend/internal/modules/system/domain"
+	"backend/internal/modules/user/domain"
+)
+
+const (
+	mfaPendingTTL     = 5 * time.Minute
+	recoveryCodeCount = 10
+)
+
+var (
+	ErrInvalidMFAToken   = errors.New("invalid or expired MFA token")
+	ErrInvalidMFACode    = errors.New("invalid authentication code")
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotStarted     = errors.New("two-factor enrollment has not been started")
+	ErrMFANotEnabled     = errors.New("two-factor authentication is not enabled")
+)
+
+type LoginResult struct {
+	*TokenPair
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+}
+
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// BeginTOTPEnrollment generates a new secret for the user. It is not enforced until
+// ConfirmTOTPEnrollment proves the authenticator app has been set up.
+func (s *AuthService) BeginTOTPEnrollment(ctx context.Context, userID uint) (*TOTPEnrollment, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	issuer, _ := s.systemRepo.GetConfig(ctx, systemDomain.ConfigKeySiteName)
+	if issuer == "" {
+		issuer = "Portfolio"
+	}
+
+	return &TOTPEnrollment{
+		Secret: secret,
+		URI:    utils.TOTPURI(issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTPEnrollment enables two-factor authentication and returns the recovery
+// codes. They are only stored hashed, so this is the only time they can be shown.
+func (s *AuthService) ConfirmTOTPEnrollment(ctx context.Context, userID uint, code string) ([]string, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrMFANotStarted
+	}
+
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now(), user.TOTPLastStep)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+	claimed, err := s.userRepo.ClaimTOTPStep(ctx, user.ID, step)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	user.TOTPEnabled = true
+	user.TOTPLastStep = step
+	user.RecoveryCodes = hashRecoveryCodes(codes)
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	audit.Record(ctx, audit.Event{Action: "auth.mfa_enabled", TargetType: "user", TargetID: audit.ID(user.ID)})
+	return codes, nil
+}
+
+// DisableTOTP turns two-factor authentication off after re-checking both factors.
+// Failures count towards the login throttle, like they would on the login form.
+func (s *AuthService) DisableTOTP(ctx context.Context, userID uint, password, code string, client ClientInfo) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return ErrMFANotEnabled
+	}
+	if err := s.throttle.Check(user.Email, client.IP, time.Now()); err != nil {
+		return err
+	}
+	if !utils.CheckPasswordHash(password, user.Password) {
+		return s.loginFailed(ctx, user.Email, &user.ID, client, "bad_password", ErrInvalidCredentials)
+	}
+	if ok, err := s.consumeSecondFactor(ctx, user, code); err != nil {
+		return err
+	} else if !ok {
+		return s.loginFailed(ctx, user.Email, &user.ID, client, "bad_mfa_code", ErrInvalidMFACode)
+	}
+	s.throttle.RecordSuccess(user.Email)
+
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+	user.TOTPLastStep = 0
+	user.RecoveryCodes = nil
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{Action: "auth.mfa_disabled", TargetType: "user", TargetID: audit.ID(user.ID)})
+	return nil
+}
+
+// CompleteMFALogin exchanges the MFA token from Login plus a TOTP or recovery code for a session.
+func (s *AuthService) CompleteMFALogin(ctx context.Context, mfaToken, code string, client ClientInfo) (*TokenPair, error) {
+	claims, err := utils.ParsePurposeToken(mfaToken, utils.PurposeMFAPending)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	user, err := s.userRepo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+	if !user.TOTPEnabled || claims.Binding != passwordFingerprint(user) {
+		return nil, ErrInvalidMFAToken
+	}
+
+	if err := s.throttle.Check(user.Email, client.IP, time.Now()); err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrAccountDisabled
+	}
+
+	if ok, err := s.consumeSecondFactor(ctx, user, code); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, s.loginFailed(ctx, user.Email, &user.ID, client, "bad_mfa_code", ErrInvalidMFACode)
+	}
+
+	s.throttle.RecordSuccess(user.Email)
+
+	tokens, err := s.startSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	recordLogin(ctx, user)
+	return tokens, nil
+}
+
+// consumeSecondFactor validates a TOTP code or a recovery code and marks it as used,
+// both in the database and on user. Marking is conditional, so when concurrent requests
+// present the same code only one of them gets true.
+func (s *AuthService) consumeSecondFactor(ctx context.Context, user *domain.User, code string) (bool, error) {
+	if step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now(), user.TOTPLastStep); ok {
+		claimed, err := s.userRepo.ClaimTOTPStep(ctx, user.ID, step)
+		if err != nil || !claimed {
+			return false, err
+		}
+		user.TOTPLastStep = step
+		return true, nil
+	}
+
+	hash := utils.HashToken(utils.NormalizeRecoveryCode(code))
+	idx := slices.Index(user.RecoveryCodes, hash)
+	if idx < 0 {
+		return false, nil
+	}
+	consumed, err := s.userRepo.ConsumeRecoveryCode(ctx, user.ID, hash)
+	if err != nil || !consumed {
+		return false, err
+	}
+	user.RecoveryCodes = slices.Delete(user.RecoveryCodes, idx, idx+1)
+	return true, nil
+}
+
+func hashRecoveryCodes(codes []string) []string {
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.HashToken(utils.NormalizeRecoveryCode(code))
+	}
+	return hashes
+}
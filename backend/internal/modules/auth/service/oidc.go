@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"backend/internal/core/audit"
+	"backend/internal/core/config"
+	"backend/internal/core/oidc"
+	"backend/internal/core/utils"
+	"backend/internal/modules/user/domain"
+	"backend/internal/modules/user/port"
+
+	"gorm.io/gorm"
+)
+
+// How long the user has to finish logging in at the provider
+const oidcPendingTTL = 10 * time.Minute
+
+var (
+	ErrOIDCDisabled         = errors.New("single sign-on is not configured")
+	ErrInvalidOIDCState     = errors.New("invalid or expired single sign-on request")
+	ErrOIDCEmailNotVerified = errors.New("the identity provider did not return a verified email")
+	ErrOIDCNoAccount        = errors.New("no account matches this identity")
+)
+
+type pendingOIDCLogin struct {
+	codeVerifier string
+	nonce        string
+	expiresAt    time.Time
+}
+
+// OIDCService signs users in through an external identity provider using the
+// authorization code flow with PKCE. Provider identities are linked to existing accounts
+// by verified email on first use, accounts are never created here.
+type OIDCService struct {
+	auth         *AuthService
+	identityRepo port.IdentityRepository
+	cfg          config.OIDCConfig
+
+	mu       sync.Mutex
+	provider oidc.Provider
+	pending  map[string]pendingOIDCLogin // Keyed by state
+}
+
+func NewOIDCService(auth *AuthService, identityRepo port.IdentityRepository, cfg config.OIDCConfig) *OIDCService {
+	return &OIDCService{
+		auth:         auth,
+		identityRepo: identityRepo,
+		cfg:          cfg,
+		pending:      map[string]pendingOIDCLogin{},
+	}
+}
+
+func (s *OIDCService) Enabled() bool {
+	return s.cfg.Provider != ""
+}
+
+// getProvider builds the provider on first use, so a provider that is down at startup
+// doesn't take the API down with it.
+func (s *OIDCService) getProvider(ctx context.Context) (oidc.Provider, error) {
+	if !s.Enabled() {
+		return nil, ErrOIDCDisabled
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.provider == nil {
+		provider, err := oidc.NewProvider(ctx, s.cfg)
+		if err != nil {
+			return nil, err
+		}
+		s.provider = provider
+	}
+	return s.provider, nil
+}
+
+// BeginLogin returns the provider URL to send the browser to and the state the callback
+// must echo back.
+func (s *OIDCService) BeginLogin(ctx context.Context) (string, string, error) {
+	provider, err := s.getProvider(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := utils.RandomString(24)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := utils.RandomString(24)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := utils.RandomString(48)
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	for key, p := range s.pending {
+		if now.After(p.expiresAt) {
+			delete(s.pending, key)
+		}
+	}
+	s.pending[state] = pendingOIDCLogin{
+		codeVerifier: verifier,
+		nonce:        nonce,
+		expiresAt:    now.Add(oidcPendingTTL),
+	}
+	s.mu.Unlock()
+
+	return provider.AuthCodeURL(state, nonce, oidc.CodeChallenge(verifier)), state, nil
+}
+
+// CompleteLogin handles the provider callback. Like Login, accounts with two-factor
+// authentication enabled get an MFA token instead of a session.
+func (s *OIDCService) CompleteLogin(ctx context.Context, state, code string, client ClientInfo) (*LoginResult, error) {
+	provider, err := s.getProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	pending, ok := s.pending[state]
+	delete(s.pending, state)
+	s.mu.Unlock()
+	if !ok || time.Now().After(pending.expiresAt) {
+		return nil, ErrInvalidOIDCState
+	}
+
+	identity, err := provider.Exchange(ctx, code, pending.codeVerifier, pending.nonce)
+	if err != nil {
+		log.Printf("oidc: code exchange failed: %v", err)
+		return nil, ErrInvalidOIDCState
+	}
+
+	user, err := s.resolveUser(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.throttle.Check(user.Email, client.IP, time.Now()); err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, s.auth.loginFailed(ctx, user.Email, &user.ID, client, "deactivated", ErrAccountDisabled)
+	}
+
+	return s.auth.finishLogin(ctx, user)
+}
+
+// resolveUser finds the account linked to the identity, linking it by email the first
+// time. Only emails the provider has verified are trusted, otherwise anyone could claim
+// an account by registering its address at the provider.
+func (s *OIDCService) resolveUser(ctx context.Context, identity *oidc.Identity) (*domain.User, error) {
+	linked, err := s.identityRepo.FindBySubject(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		user, err := s.auth.userRepo.FindByID(ctx, linked.UserID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrOIDCNoAccount
+			}
+			return nil, err
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, ErrOIDCEmailNotVerified
+	}
+
+	user, err := s.auth.userRepo.FindByEmail(ctx, identity.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOIDCNoAccount
+		}
+		return nil, err
+	}
+
+	link := &domain.Identity{
+		UserID:   user.ID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}
+	if err := s.identityRepo.Create(ctx, link); err != nil {
+		return nil, err
+	}
+
+	audit.Record(ctx, audit.Event{
+		ActorID:    &user.ID,
+		Action:     "auth.identity_linked",
+		TargetType: "user",
+		TargetID:   audit.ID(user.ID),
+		Changes:    audit.Diff(nil, link),
+	})
+	return user, nil
+}
@@ -0,0 +1,187 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"backend/internal/core/config"
+	"backend/internal/core/oidc/mockprovider"
+	"backend/internal/core/rbac"
+	"backend/internal/modules/auth/service"
+	"backend/internal/modules/auth/service/authtest"
+	"backend/internal/modules/user/domain"
+	"backend/internal/modules/user/repository"
+)
+
+const (
+	testClientID    = "portfolio"
+	testRedirectURL = "http://localhost/api/auth/oidc/callback"
+)
+
+type oidcFixture struct {
+	svc   *service.OIDCService
+	users *repository.MemoryUserRepository
+}
+
+// newOIDCFixture runs the callback flow against the mock provider with memory repositories.
+func newOIDCFixture(t *testing.T) *oidcFixture {
+	t.Helper()
+	auth := authtest.New(t, nil)
+
+	server := httptest.NewUnstartedServer(nil)
+	provider, err := mockprovider.New(mockprovider.Config{
+		Issuer:       "http://" + server.Listener.Addr().String(),
+		ClientID:     testClientID,
+		ClientSecret: "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Config.Handler = provider.Handler()
+	server.Start()
+	t.Cleanup(server.Close)
+
+	svc := service.NewOIDCService(auth.Auth, repository.NewMemoryIdentityRepository(), config.OIDCConfig{
+		Provider:     "oidc",
+		Issuer:       server.URL,
+		ClientID:     testClientID,
+		ClientSecret: "secret",
+		RedirectURL:  testRedirectURL,
+		Scopes:       "openid email",
+	})
+	return &oidcFixture{svc: svc, users: auth.Users}
+}
+
+func (f *oidcFixture) createUser(t *testing.T, email string) *domain.User {
+	t.Helper()
+	user := &domain.User{Username: strings.Split(email, "@")[0], Email: email, Password: "unused", Role: rbac.RoleAuthor}
+	if err := f.users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// authorize starts a login and signs in at the provider, returning the state and code
+// the provider redirects back with. edit may change the authorization request first.
+func (f *oidcFixture) authorize(t *testing.T, email string, verified bool, edit func(url.Values)) (string, string) {
+	t.Helper()
+
+	authURL, _, err := f.svc.BeginLogin(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	form := target.Query()
+	if edit != nil {
+		edit(form)
+	}
+	form.Set("email", email)
+	if verified {
+		form.Set("email_verified", "true")
+	}
+	target.RawQuery = ""
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.PostForm(target.String(), form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize returned %d", resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return location.Query().Get("state"), location.Query().Get("code")
+}
+
+func TestOIDCCallbackLinksExistingAccount(t *testing.T) {
+	f := newOIDCFixture(t)
+	user := f.createUser(t, "author@example.com")
+
+	state, code := f.authorize(t, "author@example.com", true, nil)
+	result, err := f.svc.CompleteLogin(context.Background(), state, code, service.ClientInfo{IP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	if result.TokenPair == nil || result.AccessToken == "" {
+		t.Fatal("expected a session")
+	}
+
+	// The identity is linked now, so the next login no longer depends on the email
+	user.Email = "renamed@example.com"
+	if err := f.users.Update(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	state, code = f.authorize(t, "author@example.com", true, nil)
+	if _, err := f.svc.CompleteLogin(context.Background(), state, code, service.ClientInfo{IP: "127.0.0.1"}); err != nil {
+		t.Fatalf("CompleteLogin through the link: %v", err)
+	}
+}
+
+func TestOIDCCallbackRejectsUnverifiedEmail(t *testing.T) {
+	f := newOIDCFixture(t)
+	f.createUser(t, "author@example.com")
+
+	state, code := f.authorize(t, "author@example.com", false, nil)
+	_, err := f.svc.CompleteLogin(context.Background(), state, code, service.ClientInfo{IP: "127.0.0.1"})
+	if !errors.Is(err, service.ErrOIDCEmailNotVerified) {
+		t.Fatalf("expected service.ErrOIDCEmailNotVerified, got %v", err)
+	}
+}
+
+func TestOIDCCallbackRejectsUnknownEmail(t *testing.T) {
+	f := newOIDCFixture(t)
+
+	state, code := f.authorize(t, "stranger@example.com", true, nil)
+	_, err := f.svc.CompleteLogin(context.Background(), state, code, service.ClientInfo{IP: "127.0.0.1"})
+	if !errors.Is(err, service.ErrOIDCNoAccount) {
+		t.Fatalf("expected service.ErrOIDCNoAccount, got %v", err)
+	}
+	if count, _ := f.users.Count(context.Background()); count != 0 {
+		t.Fatalf("expected no account to be created, found %d", count)
+	}
+}
+
+func TestOIDCCallbackRejectsStateMismatch(t *testing.T) {
+	f := newOIDCFixture(t)
+	f.createUser(t, "author@example.com")
+
+	state, code := f.authorize(t, "author@example.com", true, nil)
+	if _, err := f.svc.CompleteLogin(context.Background(), "forged", code, service.ClientInfo{}); !errors.Is(err, service.ErrInvalidOIDCState) {
+		t.Fatalf("expected service.ErrInvalidOIDCState for an unknown state, got %v", err)
+	}
+
+	if _, err := f.svc.CompleteLogin(context.Background(), state, code, service.ClientInfo{}); err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	if _, err := f.svc.CompleteLogin(context.Background(), state, code, service.ClientInfo{}); !errors.Is(err, service.ErrInvalidOIDCState) {
+		t.Fatalf("expected service.ErrInvalidOIDCState for a replayed state, got %v", err)
+	}
+}
+
+func TestOIDCCallbackRejectsNonceMismatch(t *testing.T) {
+	f := newOIDCFixture(t)
+	f.createUser(t, "author@example.com")
+
+	state, code := f.authorize(t, "author@example.com", true, func(form url.Values) {
+		form.Set("nonce", "forged")
+	})
+	_, err := f.svc.CompleteLogin(context.Background(), state, code, service.ClientInfo{})
+	if !errors.Is(err, service.ErrInvalidOIDCState) {
+		t.Fatalf("expected service.ErrInvalidOIDCState, got %v", err)
+	}
+}
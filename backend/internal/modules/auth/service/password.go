@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"backend/internal/core/audit"
+	"backend/internal/core/config"
+	"backend/internal/core/mailer"
+	"backend/internal/core/utils"
+	"backend/internal/core/validation"
+	"backend/internal/modules/user/domain"
+
+	"gorm.io/gorm"
+)
+
+const passwordResetTTL = 30 * time.Minute
+
+var (
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+)
+
+// passwordFingerprint changes whenever the password does, so tokens bound to it are
+// single-use for password resets and die with the old password otherwise.
+func passwordFingerprint(user *domain.User) string {
+	return utils.HashToken(user.Password)[:16]
+}
+
+// ForgotPassword emails a reset link if the address belongs to an account. It never
+// reports whether the account exists. Requests are throttled per address and per client
+// IP, whether or not the account exists, so nobody can flood an inbox with resets.
+func (s *AuthService) ForgotPassword(ctx context.Context, email string, client ClientInfo) error {
+	now := time.Now()
+	if err := s.resetThrottle.Check(email, client.IP, now); err != nil {
+		return err
+	}
+	s.resetThrottle.RecordFailure(email, client.IP, now)
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := utils.GeneratePurposeToken(user.ID, utils.PurposePasswordReset, passwordFingerprint(user), passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := config.PasswordResetURL + "?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone requested a password reset for your account. "+
+			"Open the link below within %d minutes to choose a new password:\n\n%s\n\n"+
+			"If this wasn't you, you can ignore this email.\n",
+			user.Username, int(passwordResetTTL.Minutes()), link),
+	}
+
+	audit.Record(ctx, audit.Event{Action: "auth.password_reset_requested", TargetType: "user", TargetID: audit.ID(user.ID)})
+
+	// Send in the background so the response time doesn't reveal whether the account exists
+	go func() {
+		if err := s.mailer.Send(context.Background(), msg); err != nil {
+			log.Printf("failed to send password reset email: %v", err)
+		}
+	}()
+	return nil
+}
+
+// ResetPassword sets a new password using a token from ForgotPassword and signs the
+// user out everywhere.
+func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	claims, err := utils.ParsePurposeToken(token, utils.PurposePasswordReset)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.userRepo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if claims.Binding != passwordFingerprint(user) {
+		return ErrInvalidResetToken
+	}
+
+	if err := s.setPassword(ctx, user, newPassword); err != nil {
+		return err
+	}
+
+	s.throttle.RecordSuccess(user.Email)
+	if err := s.refreshRepo.RevokeAllForUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{
+		ActorID:    &user.ID,
+		Action:     "auth.password_reset",
+		TargetType: "user",
+		TargetID:   audit.ID(user.ID),
+	})
+	return nil
+}
+
+// ChangePassword updates the password of a signed-in user, revokes their other sessions
+// and returns a fresh session for the current client.
+func (s *AuthService) ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string, client ClientInfo) (*TokenPair, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.throttle.Check(user.Email, client.IP, time.Now()); err != nil {
+		return nil, err
+	}
+	if !utils.CheckPasswordHash(currentPassword, user.Password) {
+		return nil, s.loginFailed(ctx, user.Email, &user.ID, client, "bad_password", ErrInvalidCredentials)
+	}
+	s.throttle.RecordSuccess(user.Email)
+
+	if err := s.setPassword(ctx, user, newPassword); err != nil {
+		return nil, err
+	}
+	if err := s.refreshRepo.RevokeAllForUser(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	audit.Record(ctx, audit.Event{Action: "auth.password_changed", TargetType: "user", TargetID: audit.ID(user.ID)})
+	return s.startSession(ctx, user)
+}
+
+func (s *AuthService) setPassword(ctx context.Context, user *domain.User, password string) error {
+	if msg := validation.Password(password, user.Username, user.Email); msg != "" {
+		return validation.Errors{"password": msg}
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+	return s.userRepo.Update(ctx, user)
+}
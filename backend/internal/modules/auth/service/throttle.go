@@ -0,0 +1,152 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/core/config"
+)
+
+// ThrottledError is returned when a login is refused before checking the password.
+type ThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool // Lockout after too many failures rather than backoff between attempts
+}
+
+func (e *ThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed attempts, locked for %s", e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("too many failed attempts, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+type failureRecord struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// LoginThrottle tracks failed logins per account and per client IP in memory, applying
+// exponential backoff and temporary lockouts.
+type LoginThrottle struct {
+	mu      sync.Mutex
+	cfg     config.LoginThrottleConfig
+	records map[string]*failureRecord
+}
+
+func NewLoginThrottle(cfg config.LoginThrottleConfig) *LoginThrottle {
+	return &LoginThrottle{
+		cfg:     cfg,
+		records: map[string]*failureRecord{},
+	}
+}
+
+func accountKey(email string) string { return "account:" + strings.ToLower(email) }
+func ipKey(ip string) string         { return "ip:" + ip }
+
+// Check returns a ThrottledError if either the account or the IP may not attempt a login yet.
+func (t *LoginThrottle) Check(email, ip string, now time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var worst *ThrottledError
+	for _, key := range []string{accountKey(email), ipKey(ip)} {
+		if err := t.check(key, now); err != nil && (worst == nil || err.RetryAfter > worst.RetryAfter) {
+			worst = err
+		}
+	}
+	if worst == nil {
+		return nil
+	}
+	return worst
+}
+
+func (t *LoginThrottle) check(key string, now time.Time) *ThrottledError {
+	rec := t.current(key, now)
+	if rec == nil {
+		return nil
+	}
+	if now.Before(rec.lockedUntil) {
+		return &ThrottledError{RetryAfter: rec.lockedUntil.Sub(now), Locked: true}
+	}
+	if next := rec.lastFailure.Add(t.backoff(rec.failures)); now.Before(next) {
+		return &ThrottledError{RetryAfter: next.Sub(now)}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed attempt against both the account and the IP.
+func (t *LoginThrottle) RecordFailure(email, ip string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.recordFailure(accountKey(email), t.cfg.MaxAccountFailures, now)
+	t.recordFailure(ipKey(ip), t.cfg.MaxIPFailures, now)
+	t.prune(now)
+}
+
+func (t *LoginThrottle) recordFailure(key string, maxFailures int, now time.Time) {
+	rec := t.current(key, now)
+	if rec == nil {
+		rec = &failureRecord{}
+		t.records[key] = rec
+	}
+	rec.failures++
+	rec.lastFailure = now
+	if maxFailures > 0 && rec.failures >= maxFailures {
+		rec.lockedUntil = now.Add(t.cfg.LockoutDuration)
+		rec.failures = 0
+	}
+}
+
+// RecordSuccess clears the account's failures. The IP keeps its history so a single
+// valid account can't be used to reset an IP that is guessing other accounts.
+func (t *LoginThrottle) RecordSuccess(email string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.records, accountKey(email))
+}
+
+// current returns the record for key, dropping it if it has gone stale.
+func (t *LoginThrottle) current(key string, now time.Time) *failureRecord {
+	rec, ok := t.records[key]
+	if !ok {
+		return nil
+	}
+	if t.isStale(rec, now) {
+		delete(t.records, key)
+		return nil
+	}
+	return rec
+}
+
+func (t *LoginThrottle) isStale(rec *failureRecord, now time.Time) bool {
+	return !now.Before(rec.lockedUntil) && now.Sub(rec.lastFailure) > t.cfg.Window
+}
+
+func (t *LoginThrottle) backoff(failures int) time.Duration {
+	over := failures - t.cfg.BackoffAfter
+	if over <= 0 {
+		return 0
+	}
+	delay := t.cfg.BackoffBase
+	for i := 1; i < over && delay < t.cfg.BackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > t.cfg.BackoffMax {
+		delay = t.cfg.BackoffMax
+	}
+	return delay
+}
+
+// prune drops stale records so the map can't grow without bound.
+func (t *LoginThrottle) prune(now time.Time) {
+	for key, rec := range t.records {
+		if t.isStale(rec, now) {
+			delete(t.records, key)
+		}
+	}
+}
@@ -17,6 +17,9 @@ type DiaryEntry struct {
 	Content    string         `json:"content"`
 	Date       time.Time      `json:"date"`
 	Visibility string         `gorm:"default:'public'" json:"visibility"` // 'public' | 'private'
+	Status     string         `gorm:"size:16;not null;default:'published';index" json:"status"`
+	PublishAt  *time.Time     `gorm:"index" json:"publish_at"`                // When the entry goes live, nil for entries older than statuses
+	Tags       []Tag          `gorm:"many2many:diary_entry_tags" json:"tags"` // Only the IDs are read from requests
 }
 
 func (DiaryEntry) TableName() string {
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"time"
+
+	"backend/internal/core/validation"
+)
+
+// Entry statuses. Only published entries are shown to the public, and only once their
+// PublishAt has passed; scheduled entries are published by the scheduler at PublishAt.
+const (
+	StatusDraft     = "draft"
+	StatusScheduled = "scheduled"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
+
+// EventPublished is published with the DiaryEntry as payload whenever an entry goes
+// live, whether directly or through the scheduler.
+const EventPublished = "diary.published"
+
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusDraft, StatusScheduled, StatusPublished, StatusArchived:
+		return true
+	}
+	return false
+}
+
+// ResolveStatus validates Status and PublishAt and settles them against now. Entries
+// without a status are published, to keep clients that predate statuses working. A
+// published entry with a future PublishAt becomes scheduled and a scheduled entry that
+// is already due becomes published.
+func (e *DiaryEntry) ResolveStatus(now time.Time) error {
+	if e.Status == "" {
+		e.Status = StatusPublished
+	}
+	if !IsValidStatus(e.Status) {
+		return validation.Errors{"status": "must be one of: draft, scheduled, published, archived"}
+	}
+
+	switch e.Status {
+	case StatusScheduled:
+		if e.PublishAt == nil {
+			return validation.Errors{"publish_at": "is required to schedule an entry"}
+		}
+		if !e.PublishAt.After(now) {
+			e.Status = StatusPublished
+		}
+	case StatusPublished:
+		if e.PublishAt == nil {
+			e.PublishAt = &now
+		} else if e.PublishAt.After(now) {
+			e.Status = StatusScheduled
+		}
+	}
+	return nil
+}
+
+// IsLive reports whether the entry is published and its publish time has passed.
+func (e *DiaryEntry) IsLive(now time.Time) bool {
+	return e.Status == StatusPublished && (e.PublishAt == nil || !e.PublishAt.After(now))
+}
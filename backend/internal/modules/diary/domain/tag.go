@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// Tag groups diary entries by topic. Entries link to any number of tags.
+type Tag struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Slug        string    `gorm:"uniqueIndex;not null" json:"slug"` // Generated from Name unless given
+	Name        string    `gorm:"not null" json:"name"`
+	Description string    `json:"description"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// TagCount is a tag with the number of its entries the public can see.
+type TagCount struct {
+	Tag   `gorm:"embedded"`
+	Count int64 `json:"count"`
+}
@@ -2,14 +2,23 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"backend/internal/core/config"
+	"backend/internal/core/db"
+	"backend/internal/core/listing"
+	"backend/internal/core/server/middleware"
+	"backend/internal/core/validation"
 	"backend/internal/modules/diary/domain"
 	"backend/internal/modules/diary/repository"
 	"backend/internal/modules/diary/service"
+	revisionRepo "backend/internal/modules/revision/repository"
+	revisionService "backend/internal/modules/revision/service"
 
 	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
 )
 
 type DiaryHandler struct {
@@ -18,24 +27,48 @@ type DiaryHandler struct {
 
 func NewDiaryHandler() *DiaryHandler {
 	repo := repository.NewPostgresDiaryRepository()
-	svc := service.NewDiaryService(repo)
+	tags := repository.NewPostgresTagRepository()
+	tx := db.NewPostgresTransactor()
+	revisions := revisionService.NewRevisionService(revisionRepo.NewPostgresRevisionRepository(), tx)
+	svc := service.NewDiaryService(repo, tags, revisions, tx)
 	return &DiaryHandler{svc: svc}
 }
 
+// Query parameters of GetDiaries, see package listing, plus tag=<slug>. Callers who
+// can't write diary entries only ever see public, published ones whatever they filter by.
+var listSpec = listing.Spec{
+	Sorts:       []string{"date", "publish_at", "created_at", "updated_at", "title"},
+	DefaultSort: "-date",
+	Filters: []listing.Filter{
+		{Param: "visibility", Column: "visibility"},
+		{Param: "status", Column: "status"},
+	},
+	DateColumn: "date",
+}
+
 func (h *DiaryHandler) GetDiaries(c context.Context, ctx *app.RequestContext) {
-	isAuth := ctx.GetBool("isAuthenticated") // Set by JWT middleware
+	canSeePrivate := ctx.GetBool(middleware.ContextCanSeePrivate) // Set by OptionalAuth
+
+	params, err := listing.FromRequest(ctx, listSpec)
+	if errs, ok := validation.As(err); ok {
+		ctx.JSON(http.StatusBadRequest, errs.Response())
+		return
+	}
 
-	entries, err := h.svc.GetAllDiaries(c, isAuth)
+	entries, total, err := h.svc.GetAllDiaries(c, canSeePrivate, ctx.Query("tag"), params)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
+	listing.SetHeaders(ctx, params, total)
 	ctx.JSON(http.StatusOK, entries)
 }
 
 func (h *DiaryHandler) GetDiary(c context.Context, ctx *app.RequestContext) {
 	slug := ctx.Param("slug")
-	entry, err := h.svc.GetDiaryBySlug(c, slug)
+	canSeePrivate := ctx.GetBool(middleware.ContextCanSeePrivate) // Set by OptionalAuth
+
+	entry, err := h.svc.GetDiaryBySlug(c, slug, canSeePrivate)
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, map[string]string{"error": "Diary entry not found"})
 		return
@@ -50,6 +83,10 @@ func (h *DiaryHandler) CreateDiary(c context.Context, ctx *app.RequestContext) {
 		return
 	}
 	if err := h.svc.CreateDiary(c, &entry); err != nil {
+		if errs, ok := validation.As(err); ok {
+			ctx.JSON(http.StatusBadRequest, errs.Response())
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
@@ -71,6 +108,10 @@ func (h *DiaryHandler) UpdateDiary(c context.Context, ctx *app.RequestContext) {
 	}
 
 	if err := h.svc.UpdateDiary(c, uint(id), &entry); err != nil {
+		if errs, ok := validation.As(err); ok {
+			ctx.JSON(http.StatusBadRequest, errs.Response())
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
@@ -85,8 +126,18 @@ func (h *DiaryHandler) DeleteDiary(c context.Context, ctx *app.RequestContext) {
 		return
 	}
 	if err := h.svc.DeleteDiary(c, uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, map[string]string{"error": "Diary entry not found"})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
 	ctx.JSON(http.StatusOK, map[string]string{"message": "Diary deleted"})
 }
+
+// RunScheduler publishes scheduled entries as they fall due, until c is done. main runs
+// it in the background.
+func (h *DiaryHandler) RunScheduler(c context.Context) {
+	h.svc.RunScheduler(c, config.DiarySchedulerInterval)
+}
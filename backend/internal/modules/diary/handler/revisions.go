@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/core/validation"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
+)
+
+func (h *DiaryHandler) GetRevisions(c context.Context, ctx *app.RequestContext) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return
+	}
+
+	revisions, err := h.svc.GetRevisions(c, uint(id))
+	if err != nil {
+		revisionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, revisions)
+}
+
+func (h *DiaryHandler) GetRevision(c context.Context, ctx *app.RequestContext) {
+	id, version, ok := revisionParams(ctx)
+	if !ok {
+		return
+	}
+
+	revision, err := h.svc.GetRevision(c, id, version)
+	if err != nil {
+		revisionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, revision)
+}
+
+// DiffRevisions compares the revisions given by ?from= and ?to=.
+func (h *DiaryHandler) DiffRevisions(c context.Context, ctx *app.RequestContext) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return
+	}
+	from, errFrom := strconv.Atoi(ctx.Query("from"))
+	to, errTo := strconv.Atoi(ctx.Query("to"))
+	if errFrom != nil || errTo != nil {
+		ctx.JSON(http.StatusBadRequest, map[string]string{"error": "from and to must be revision versions"})
+		return
+	}
+
+	changes, err := h.svc.DiffRevisions(c, uint(id), from, to)
+	if err != nil {
+		revisionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, map[string]interface{}{"from": from, "to": to, "changes": changes})
+}
+
+func (h *DiaryHandler) RestoreRevision(c context.Context, ctx *app.RequestContext) {
+	id, version, ok := revisionParams(ctx)
+	if !ok {
+		return
+	}
+
+	entry, err := h.svc.RestoreRevision(c, id, version)
+	if err != nil {
+		revisionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, entry)
+}
+
+func revisionParams(ctx *app.RequestContext) (uint, int, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return 0, 0, false
+	}
+	version, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid version"})
+		return 0, 0, false
+	}
+	return uint(id), version, true
+}
+
+func revisionError(ctx *app.RequestContext, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, map[string]string{"error": "Diary entry or revision not found"})
+		return
+	}
+	if errs, ok := validation.As(err); ok {
+		ctx.JSON(http.StatusBadRequest, errs.Response())
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
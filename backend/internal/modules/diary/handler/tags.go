@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/core/server/middleware"
+	"backend/internal/core/validation"
+	"backend/internal/modules/diary/domain"
+	"backend/internal/modules/diary/repository"
+	"backend/internal/modules/diary/service"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
+)
+
+type TagHandler struct {
+	svc *service.TagService
+}
+
+func NewTagHandler() *TagHandler {
+	repo := repository.NewPostgresTagRepository()
+	svc := service.NewTagService(repo)
+	return &TagHandler{svc: svc}
+}
+
+// GetTags lists the tags with their number of public entries. Callers who can write
+// diary entries also get the tags that have none, to manage them.
+func (h *TagHandler) GetTags(c context.Context, ctx *app.RequestContext) {
+	canSeePrivate := ctx.GetBool(middleware.ContextCanSeePrivate) // Set by OptionalAuth
+
+	tags, err := h.svc.GetTags(c, canSeePrivate)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, tags)
+}
+
+func (h *TagHandler) CreateTag(c context.Context, ctx *app.RequestContext) {
+	var tag domain.Tag
+	if err := ctx.BindAndValidate(&tag); err != nil {
+		ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := h.svc.CreateTag(c, &tag); err != nil {
+		tagError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, tag)
+}
+
+func (h *TagHandler) UpdateTag(c context.Context, ctx *app.RequestContext) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return
+	}
+
+	var tag domain.Tag
+	if err := ctx.BindAndValidate(&tag); err != nil {
+		ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := h.svc.UpdateTag(c, uint(id), &tag); err != nil {
+		tagError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, tag)
+}
+
+func (h *TagHandler) DeleteTag(c context.Context, ctx *app.RequestContext) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return
+	}
+	if err := h.svc.DeleteTag(c, uint(id)); err != nil {
+		tagError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, map[string]string{"message": "Tag deleted"})
+}
+
+func tagError(ctx *app.RequestContext, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, map[string]string{"error": "Tag not found"})
+		return
+	}
+	if errs, ok := validation.As(err); ok {
+		ctx.JSON(http.StatusBadRequest, errs.Response())
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
@@ -1,15 +1,40 @@
 package port
 
 import (
+	"backend/internal/core/listing"
 	"backend/internal/modules/diary/domain"
 	"context"
+	"time"
 )
 
 type DiaryRepository interface {
 	Create(ctx context.Context, entry *domain.DiaryEntry) error
-	FindAll(ctx context.Context, includePrivate bool) ([]domain.DiaryEntry, error)
+	// FindAll returns a page of entries and the total number of matches. Without
+	// includePrivate only public entries that are live at now can match. A non-empty
+	// tag limits the entries to those linked to the tag with that slug.
+	FindAll(ctx context.Context, includePrivate bool, tag string, now time.Time, params listing.Params) ([]domain.DiaryEntry, int64, error)
 	FindBySlug(ctx context.Context, slug string) (*domain.DiaryEntry, error)
 	FindByID(ctx context.Context, id uint) (*domain.DiaryEntry, error)
 	Update(ctx context.Context, entry *domain.DiaryEntry) error
 	Delete(ctx context.Context, id uint) error
+	// PublishDue publishes the scheduled entries whose PublishAt is at or before now and
+	// returns them. Each entry is only returned to one caller, even across instances.
+	PublishDue(ctx context.Context, now time.Time) ([]domain.DiaryEntry, error)
+}
+
+type TagRepository interface {
+	Create(ctx context.Context, tag *domain.Tag) error
+	// FindAll returns every tag, ordered by name.
+	FindAll(ctx context.Context) ([]domain.Tag, error)
+	// FindAllWithCounts returns every tag, ordered by name, with the number of its
+	// entries that are public and live at now.
+	FindAllWithCounts(ctx context.Context, now time.Time) ([]domain.TagCount, error)
+	FindByID(ctx context.Context, id uint) (*domain.Tag, error)
+	// FindByIDs returns the tags that exist among ids, ordered by name.
+	FindByIDs(ctx context.Context, ids []uint) ([]domain.Tag, error)
+	// SlugExists reports whether a tag other than exceptID has slug.
+	SlugExists(ctx context.Context, slug string, exceptID uint) (bool, error)
+	Update(ctx context.Context, tag *domain.Tag) error
+	// Delete removes the tag and unlinks it from its entries.
+	Delete(ctx context.Context, id uint) error
 }
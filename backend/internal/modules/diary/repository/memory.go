@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"backend/internal/core/listing"
+	"backend/internal/modules/diary/domain"
+	"backend/internal/modules/diary/port"
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryDiaryRepository keeps entries in a map, for tests and tools that run without a
+// database. Like the Postgres repository it hands out copies, never the stored values.
+// It also holds the tags of MemoryTagRepository; entries only store the IDs of their
+// tags, which are looked up on every read like the Postgres join does.
+type MemoryDiaryRepository struct {
+	mu        sync.RWMutex
+	entries   map[uint]domain.DiaryEntry
+	nextID    uint
+	tags      map[uint]domain.Tag
+	nextTagID uint
+}
+
+var _ port.DiaryRepository = (*MemoryDiaryRepository)(nil)
+
+func NewMemoryDiaryRepository() *MemoryDiaryRepository {
+	return &MemoryDiaryRepository{entries: make(map[uint]domain.DiaryEntry), tags: make(map[uint]domain.Tag)}
+}
+
+func (r *MemoryDiaryRepository) Create(ctx context.Context, entry *domain.DiaryEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.slugTaken(entry.Slug, 0) {
+		return gorm.ErrDuplicatedKey
+	}
+
+	r.nextID++
+	now := time.Now()
+	entry.ID = r.nextID
+	entry.CreatedAt, entry.UpdatedAt = now, now
+	if entry.Visibility == "" {
+		entry.Visibility = "public"
+	}
+	if entry.Status == "" {
+		entry.Status = domain.StatusPublished
+	}
+	r.entries[entry.ID] = r.stored(*entry)
+	return nil
+}
+
+func (r *MemoryDiaryRepository) FindAll(ctx context.Context, includePrivate bool, tag string, now time.Time, params listing.Params) ([]domain.DiaryEntry, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var entries []domain.DiaryEntry
+	for _, entry := range r.entries {
+		entry = r.loaded(entry)
+		if !includePrivate && !(entry.Visibility == "public" && entry.IsLive(now)) {
+			continue
+		}
+		if tag != "" && !slices.ContainsFunc(entry.Tags, func(t domain.Tag) bool { return t.Slug == tag }) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	page, total := listing.Slice(entries, params)
+	return page, total, nil
+}
+
+func (r *MemoryDiaryRepository) FindBySlug(ctx context.Context, slug string) (*domain.DiaryEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, entry := range r.entries {
+		if entry.Slug == slug {
+			entry = r.loaded(entry)
+			return &entry, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryDiaryRepository) FindByID(ctx context.Context, id uint) (*domain.DiaryEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.entries[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	entry = r.loaded(entry)
+	return &entry, nil
+}
+
+func (r *MemoryDiaryRepository) Update(ctx context.Context, entry *domain.DiaryEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.entries[entry.ID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	if r.slugTaken(entry.Slug, entry.ID) {
+		return gorm.ErrDuplicatedKey
+	}
+
+	entry.UpdatedAt = time.Now()
+	r.entries[entry.ID] = r.stored(*entry)
+	return nil
+}
+
+func (r *MemoryDiaryRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.entries, id)
+	return nil
+}
+
+func (r *MemoryDiaryRepository) PublishDue(ctx context.Context, now time.Time) ([]domain.DiaryEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var entries []domain.DiaryEntry
+	for id, entry := range r.entries {
+		if entry.Status == domain.StatusScheduled && entry.PublishAt != nil && !entry.PublishAt.After(now) {
+			entry.Status = domain.StatusPublished
+			entry.UpdatedAt = now
+			r.entries[id] = entry
+			entries = append(entries, r.loaded(entry))
+		}
+	}
+	return entries, nil
+}
+
+func (r *MemoryDiaryRepository) slugTaken(slug string, exceptID uint) bool {
+	for id, entry := range r.entries {
+		if id != exceptID && entry.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// stored strips the tags of entry down to the IDs of existing ones.
+func (r *MemoryDiaryRepository) stored(entry domain.DiaryEntry) domain.DiaryEntry {
+	var tags []domain.Tag
+	for _, tag := range entry.Tags {
+		if _, ok := r.tags[tag.ID]; ok && !slices.ContainsFunc(tags, func(t domain.Tag) bool { return t.ID == tag.ID }) {
+			tags = append(tags, domain.Tag{ID: tag.ID})
+		}
+	}
+	entry.Tags = tags
+	return entry
+}
+
+// loaded fills in the current tags of a stored entry, ordered by name like Postgres.
+func (r *MemoryDiaryRepository) loaded(entry domain.DiaryEntry) domain.DiaryEntry {
+	tags := []domain.Tag{}
+	for _, tag := range entry.Tags {
+		if t, ok := r.tags[tag.ID]; ok {
+			tags = append(tags, t)
+		}
+	}
+	sortTags(tags)
+	entry.Tags = tags
+	return entry
+}
+
+// Snapshot implements db.Snapshotter, so a MemoryTransactor can undo writes made in a
+// transaction that fails.
+func (r *MemoryDiaryRepository) Snapshot() func() {
+	r.mu.RLock()
+	entries, nextID, tags, nextTagID := maps.Clone(r.entries), r.nextID, maps.Clone(r.tags), r.nextTagID
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		r.entries, r.nextID, r.tags, r.nextTagID = entries, nextID, tags, nextTagID
+		r.mu.Unlock()
+	}
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"backend/internal/modules/diary/domain"
+	"backend/internal/modules/diary/port"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryTagRepository manages the tags of a MemoryDiaryRepository, which needs them to
+// resolve the tags of its entries.
+type MemoryTagRepository struct {
+	diaries *MemoryDiaryRepository
+}
+
+var _ port.TagRepository = (*MemoryTagRepository)(nil)
+
+func NewMemoryTagRepository(diaries *MemoryDiaryRepository) *MemoryTagRepository {
+	return &MemoryTagRepository{diaries: diaries}
+}
+
+func (r *MemoryTagRepository) Create(ctx context.Context, tag *domain.Tag) error {
+	d := r.diaries
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if r.slugTaken(tag.Slug, 0) {
+		return gorm.ErrDuplicatedKey
+	}
+
+	d.nextTagID++
+	now := time.Now()
+	tag.ID = d.nextTagID
+	tag.CreatedAt, tag.UpdatedAt = now, now
+	d.tags[tag.ID] = *tag
+	return nil
+}
+
+func (r *MemoryTagRepository) FindAll(ctx context.Context) ([]domain.Tag, error) {
+	d := r.diaries
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	tags := make([]domain.Tag, 0, len(d.tags))
+	for _, tag := range d.tags {
+		tags = append(tags, tag)
+	}
+	sortTags(tags)
+	return tags, nil
+}
+
+func (r *MemoryTagRepository) FindAllWithCounts(ctx context.Context, now time.Time) ([]domain.TagCount, error) {
+	tags, _ := r.FindAll(ctx)
+
+	d := r.diaries
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	counts := make([]domain.TagCount, len(tags))
+	for i, tag := range tags {
+		counts[i].Tag = tag
+		for _, entry := range d.entries {
+			if entry.Visibility == "public" && entry.IsLive(now) &&
+				slices.ContainsFunc(entry.Tags, func(t domain.Tag) bool { return t.ID == tag.ID }) {
+				counts[i].Count++
+			}
+		}
+	}
+	return counts, nil
+}
+
+func (r *MemoryTagRepository) FindByID(ctx context.Context, id uint) (*domain.Tag, error) {
+	d := r.diaries
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	tag, ok := d.tags[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &tag, nil
+}
+
+func (r *MemoryTagRepository) FindByIDs(ctx context.Context, ids []uint) ([]domain.Tag, error) {
+	d := r.diaries
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	tags := []domain.Tag{}
+	for _, tag := range d.tags {
+		if slices.Contains(ids, tag.ID) {
+			tags = append(tags, tag)
+		}
+	}
+	sortTags(tags)
+	return tags, nil
+}
+
+func (r *MemoryTagRepository) SlugExists(ctx context.Context, slug string, exceptID uint) (bool, error) {
+	d := r.diaries
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return r.slugTaken(slug, exceptID), nil
+}
+
+func (r *MemoryTagRepository) Update(ctx context.Context, tag *domain.Tag) error {
+	d := r.diaries
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.tags[tag.ID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	if r.slugTaken(tag.Slug, tag.ID) {
+		return gorm.ErrDuplicatedKey
+	}
+
+	tag.UpdatedAt = time.Now()
+	d.tags[tag.ID] = *tag
+	return nil
+}
+
+func (r *MemoryTagRepository) Delete(ctx context.Context, id uint) error {
+	d := r.diaries
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.tags, id)
+	for entryID, entry := range d.entries {
+		d.entries[entryID] = d.stored(entry)
+	}
+	return nil
+}
+
+func (r *MemoryTagRepository) slugTaken(slug string, exceptID uint) bool {
+	for id, tag := range r.diaries.tags {
+		if id != exceptID && tag.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
+
+func sortTags(tags []domain.Tag) {
+	slices.SortFunc(tags, func(a, b domain.Tag) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return int(a.ID) - int(b.ID)
+	})
+}
@@ -2,9 +2,13 @@ package repository
 
 import (
 	"backend/internal/core/db"
+	"backend/internal/core/listing"
 	"backend/internal/modules/diary/domain"
 	"backend/internal/modules/diary/port"
 	"context"
+	"time"
+
+	"gorm.io/gorm/clause"
 )
 
 type PostgresDiaryRepository struct{}
@@ -16,35 +20,74 @@ func NewPostgresDiaryRepository() *PostgresDiaryRepository {
 }
 
 func (r *PostgresDiaryRepository) Create(ctx context.Context, entry *domain.DiaryEntry) error {
-	return db.DB.WithContext(ctx).Create(entry).Error
+	// Link the tags without writing to them, they are managed through the tag endpoints
+	return db.Conn(ctx).Omit("Tags.*").Create(entry).Error
 }
 
-func (r *PostgresDiaryRepository) FindAll(ctx context.Context, includePrivate bool) ([]domain.DiaryEntry, error) {
+func (r *PostgresDiaryRepository) FindAll(ctx context.Context, includePrivate bool, tag string, now time.Time, params listing.Params) ([]domain.DiaryEntry, int64, error) {
 	var entries []domain.DiaryEntry
-	query := db.DB.WithContext(ctx).Order("date desc")
+	query := preloadTags(db.Conn(ctx))
 	if !includePrivate {
-		query = query.Where("visibility = ?", "public")
+		query = query.Where("visibility = ?", "public").
+			Where("status = ?", domain.StatusPublished).
+			Where("publish_at IS NULL OR publish_at <= ?", now)
 	}
-	err := query.Find(&entries).Error
-	return entries, err
+	if tag != "" {
+		query = query.Where("id IN (?)", db.Conn(ctx).Table("diary_entry_tags").
+			Select("diary_entry_tags.diary_entry_id").
+			Joins("JOIN tags ON tags.id = diary_entry_tags.tag_id").
+			Where("tags.slug = ?", tag))
+	}
+	total, err := listing.Find(query, params, &entries)
+	return entries, total, err
 }
 
 func (r *PostgresDiaryRepository) FindBySlug(ctx context.Context, slug string) (*domain.DiaryEntry, error) {
 	var entry domain.DiaryEntry
-	err := db.DB.WithContext(ctx).Where("slug = ?", slug).First(&entry).Error
+	err := preloadTags(db.Conn(ctx)).Where("slug = ?", slug).First(&entry).Error
 	return &entry, err
 }
 
 func (r *PostgresDiaryRepository) FindByID(ctx context.Context, id uint) (*domain.DiaryEntry, error) {
 	var entry domain.DiaryEntry
-	err := db.DB.WithContext(ctx).First(&entry, id).Error
+	err := preloadTags(db.Conn(ctx)).First(&entry, id).Error
 	return &entry, err
 }
 
 func (r *PostgresDiaryRepository) Update(ctx context.Context, entry *domain.DiaryEntry) error {
-	return db.DB.WithContext(ctx).Save(entry).Error
+	return db.Transaction(ctx, func(ctx context.Context) error {
+		if err := db.Conn(ctx).Omit("Tags").Save(entry).Error; err != nil {
+			return err
+		}
+		tags := db.Conn(ctx).Model(entry).Omit("Tags.*").Association("Tags")
+		if len(entry.Tags) == 0 {
+			return tags.Clear()
+		}
+		return tags.Replace(entry.Tags)
+	})
 }
 
 func (r *PostgresDiaryRepository) Delete(ctx context.Context, id uint) error {
-	return db.DB.WithContext(ctx).Delete(&domain.DiaryEntry{}, id).Error
+	return db.Conn(ctx).Delete(&domain.DiaryEntry{}, id).Error
+}
+
+func (r *PostgresDiaryRepository) PublishDue(ctx context.Context, now time.Time) ([]domain.DiaryEntry, error) {
+	// A single conditional UPDATE, so concurrent schedulers never publish the same entry twice
+	var entries []domain.DiaryEntry
+	err := db.Conn(ctx).Model(&entries).
+		Clauses(clause.Returning{}).
+		Where("status = ? AND publish_at <= ?", domain.StatusScheduled, now).
+		Updates(map[string]any{"status": domain.StatusPublished, "updated_at": now}).Error
+	if err != nil || len(entries) == 0 {
+		return entries, err
+	}
+
+	// RETURNING only covers the entry columns, reload the entries with their tags
+	ids := make([]uint, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+	entries = nil
+	err = preloadTags(db.Conn(ctx)).Order("id").Find(&entries, ids).Error
+	return entries, err
 }
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"backend/internal/core/db"
+	"backend/internal/modules/diary/domain"
+	"backend/internal/modules/diary/port"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PostgresTagRepository struct{}
+
+var _ port.TagRepository = (*PostgresTagRepository)(nil)
+
+func NewPostgresTagRepository() *PostgresTagRepository {
+	return &PostgresTagRepository{}
+}
+
+func (r *PostgresTagRepository) Create(ctx context.Context, tag *domain.Tag) error {
+	return db.Conn(ctx).Create(tag).Error
+}
+
+func (r *PostgresTagRepository) FindAll(ctx context.Context) ([]domain.Tag, error) {
+	var tags []domain.Tag
+	err := db.Conn(ctx).Order("name").Order("id").Find(&tags).Error
+	return tags, err
+}
+
+func (r *PostgresTagRepository) FindAllWithCounts(ctx context.Context, now time.Time) ([]domain.TagCount, error) {
+	var tags []domain.TagCount
+	err := db.Conn(ctx).Model(&domain.Tag{}).
+		Select("tags.*, COUNT(diary_entries.id) AS count").
+		Joins("LEFT JOIN diary_entry_tags ON diary_entry_tags.tag_id = tags.id").
+		Joins("LEFT JOIN diary_entries ON diary_entries.id = diary_entry_tags.diary_entry_id"+
+			" AND diary_entries.deleted_at IS NULL"+
+			" AND diary_entries.visibility = ? AND diary_entries.status = ?"+
+			" AND (diary_entries.publish_at IS NULL OR diary_entries.publish_at <= ?)",
+			"public", domain.StatusPublished, now).
+		Group("tags.id").
+		Order("tags.name").Order("tags.id").
+		Scan(&tags).Error
+	return tags, err
+}
+
+func (r *PostgresTagRepository) FindByID(ctx context.Context, id uint) (*domain.Tag, error) {
+	var tag domain.Tag
+	err := db.Conn(ctx).First(&tag, id).Error
+	return &tag, err
+}
+
+func (r *PostgresTagRepository) FindByIDs(ctx context.Context, ids []uint) ([]domain.Tag, error) {
+	tags := []domain.Tag{}
+	if len(ids) == 0 {
+		return tags, nil
+	}
+	err := db.Conn(ctx).Where("id IN ?", ids).Order("name").Order("id").Find(&tags).Error
+	return tags, err
+}
+
+func (r *PostgresTagRepository) SlugExists(ctx context.Context, slug string, exceptID uint) (bool, error) {
+	var count int64
+	err := db.Conn(ctx).Model(&domain.Tag{}).Where("slug = ? AND id <> ?", slug, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *PostgresTagRepository) Update(ctx context.Context, tag *domain.Tag) error {
+	return db.Conn(ctx).Save(tag).Error
+}
+
+func (r *PostgresTagRepository) Delete(ctx context.Context, id uint) error {
+	return db.Transaction(ctx, func(ctx context.Context) error {
+		if err := db.Conn(ctx).Exec("DELETE FROM diary_entry_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		return db.Conn(ctx).Delete(&domain.Tag{}, id).Error
+	})
+}
+
+// preloadTags loads the tags of the queried entries, ordered by name.
+func preloadTags(query *gorm.DB) *gorm.DB {
+	return query.Preload("Tags", func(db *gorm.DB) *gorm.DB {
+		return db.Order("tags.name")
+	})
+}
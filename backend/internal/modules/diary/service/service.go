@@ -1,47 +1,295 @@
 package service
 
 import (
+	"backend/internal/core/audit"
+	"backend/internal/core/db"
+	"backend/internal/core/events"
+	"backend/internal/core/listing"
+	"backend/internal/core/validation"
 	"backend/internal/modules/diary/domain"
 	"backend/internal/modules/diary/port"
+	revisionDomain "backend/internal/modules/revision/domain"
+	revisionService "backend/internal/modules/revision/service"
 	"context"
+	"encoding/json"
+	"log"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+// Entity type of diary entries in the revision history
+const revisionType = "diary"
+
 type DiaryService struct {
-	repo port.DiaryRepository
+	repo      port.DiaryRepository
+	tags      port.TagRepository
+	revisions *revisionService.RevisionService
+	tx        db.Transactor
 }
 
-func NewDiaryService(repo port.DiaryRepository) *DiaryService {
-	return &DiaryService{repo: repo}
+func NewDiaryService(repo port.DiaryRepository, tags port.TagRepository, revisions *revisionService.RevisionService, tx db.Transactor) *DiaryService {
+	return &DiaryService{repo: repo, tags: tags, revisions: revisions, tx: tx}
 }
 
 func (s *DiaryService) CreateDiary(ctx context.Context, entry *domain.DiaryEntry) error {
-	return s.repo.Create(ctx, entry)
+	if err := entry.ResolveStatus(time.Now()); err != nil {
+		return err
+	}
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if entry.Tags, err = s.resolveTags(ctx, entry.Tags); err != nil {
+			return err
+		}
+		if err := s.repo.Create(ctx, entry); err != nil {
+			return err
+		}
+		return s.revisions.Record(ctx, revisionType, entry.ID, nil, entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{
+		Action:     "diary.create",
+		TargetType: "diary",
+		TargetID:   audit.ID(entry.ID),
+		Changes:    audit.Diff(nil, entry),
+	})
+	if entry.Status == domain.StatusPublished {
+		publishEvent(ctx, entry)
+	}
+	return nil
 }
 
-func (s *DiaryService) GetAllDiaries(ctx context.Context, includePrivate bool) ([]domain.DiaryEntry, error) {
-	return s.repo.FindAll(ctx, includePrivate)
+// GetAllDiaries lists entries, only those tagged with the tag slug if it isn't empty.
+func (s *DiaryService) GetAllDiaries(ctx context.Context, includePrivate bool, tag string, params listing.Params) ([]domain.DiaryEntry, int64, error) {
+	return s.repo.FindAll(ctx, includePrivate, tag, time.Now(), params)
 }
 
-func (s *DiaryService) GetDiaryBySlug(ctx context.Context, slug string) (*domain.DiaryEntry, error) {
-	return s.repo.FindBySlug(ctx, slug)
+func (s *DiaryService) GetDiaryBySlug(ctx context.Context, slug string, includePrivate bool) (*domain.DiaryEntry, error) {
+	entry, err := s.repo.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	// Hide private and unpublished entries from the public as if they didn't exist
+	if !includePrivate && (entry.Visibility == "private" || !entry.IsLive(time.Now())) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return entry, nil
 }
 
 func (s *DiaryService) UpdateDiary(ctx context.Context, id uint, input *domain.DiaryEntry) error {
-	entry, err := s.repo.FindByID(ctx, id)
+	return s.updateDiary(ctx, id, input, "diary.update")
+}
+
+// updateDiary saves the editable fields of input over the entry and stores the result
+// as a new revision, so no edit can destroy earlier content.
+func (s *DiaryService) updateDiary(ctx context.Context, id uint, input *domain.DiaryEntry, action string) error {
+	var entry *domain.DiaryEntry
+	var before domain.DiaryEntry
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if entry, err = s.repo.FindByID(ctx, id); err != nil {
+			return err
+		}
+		before = *entry
+
+		entry.Slug = input.Slug
+		entry.Title = input.Title
+		entry.Excerpt = input.Excerpt
+		entry.Content = input.Content
+		entry.Date = input.Date
+		entry.Visibility = input.Visibility
+		// Clients that predate statuses don't send them, which must not re-stamp the publish time
+		if input.Status != "" {
+			entry.Status = input.Status
+		}
+		if input.PublishAt != nil {
+			entry.PublishAt = input.PublishAt
+		}
+		// Likewise a missing tags field keeps the tags, an empty list removes them
+		if input.Tags != nil {
+			if entry.Tags, err = s.resolveTags(ctx, input.Tags); err != nil {
+				return err
+			}
+		}
+
+		if err := entry.ResolveStatus(time.Now()); err != nil {
+			return err
+		}
+		if err := s.repo.Update(ctx, entry); err != nil {
+			return err
+		}
+		return s.revisions.Record(ctx, revisionType, entry.ID, &before, entry)
+	})
 	if err != nil {
 		return err
 	}
 
-	entry.Slug = input.Slug
-	entry.Title = input.Title
-	entry.Excerpt = input.Excerpt
-	entry.Content = input.Content
-	entry.Date = input.Date
-	entry.Visibility = input.Visibility
-
-	return s.repo.Update(ctx, entry)
+	audit.Record(ctx, audit.Event{
+		Action:     action,
+		TargetType: "diary",
+		TargetID:   audit.ID(entry.ID),
+		Changes:    audit.Diff(&before, entry),
+	})
+	if entry.Status == domain.StatusPublished && before.Status != domain.StatusPublished {
+		publishEvent(ctx, entry)
+	}
+	*input = *entry // Hand back what was stored, including the resolved status
+	return nil
 }
 
 func (s *DiaryService) DeleteDiary(ctx context.Context, id uint) error {
-	return s.repo.Delete(ctx, id)
+	before, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{
+		Action:     "diary.delete",
+		TargetType: "diary",
+		TargetID:   audit.ID(id),
+		Changes:    audit.Diff(before, nil),
+	})
+	return nil
+}
+
+// PublishDue publishes the scheduled entries that are due and returns how many there
+// were. Each publication is stored as a revision in the same transaction.
+func (s *DiaryService) PublishDue(ctx context.Context) (int, error) {
+	var entries []domain.DiaryEntry
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if entries, err = s.repo.PublishDue(ctx, time.Now()); err != nil {
+			return err
+		}
+		for i := range entries {
+			if err := s.revisions.Record(ctx, revisionType, entries[i].ID, nil, &entries[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		audit.Record(ctx, audit.Event{
+			Action:     "diary.publish",
+			TargetType: "diary",
+			TargetID:   audit.ID(entry.ID),
+			Changes: audit.Diff(
+				map[string]string{"status": domain.StatusScheduled},
+				map[string]string{"status": domain.StatusPublished},
+			),
+		})
+		publishEvent(ctx, entry)
+	}
+	return len(entries), nil
+}
+
+// RunScheduler calls PublishDue every interval until ctx is done.
+func (s *DiaryService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := s.PublishDue(ctx); err != nil {
+			log.Printf("diary scheduler: %v", err)
+		} else if n > 0 {
+			log.Printf("diary scheduler: published %d entries", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetRevisions lists the revisions of an entry, newest first.
+func (s *DiaryService) GetRevisions(ctx context.Context, id uint) ([]revisionDomain.Revision, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.revisions.List(ctx, revisionType, id)
+}
+
+func (s *DiaryService) GetRevision(ctx context.Context, id uint, version int) (*revisionDomain.Revision, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.revisions.Get(ctx, revisionType, id, version)
+}
+
+// DiffRevisions returns the fields that changed between two revisions of an entry.
+func (s *DiaryService) DiffRevisions(ctx context.Context, id uint, from, to int) (map[string]audit.Change, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.revisions.Diff(ctx, revisionType, id, from, to)
+}
+
+// RestoreRevision makes a revision the current version of the entry. The restore is
+// itself stored as a new revision, so it can be undone the same way.
+func (s *DiaryService) RestoreRevision(ctx context.Context, id uint, version int) (*domain.DiaryEntry, error) {
+	revision, err := s.GetRevision(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot domain.DiaryEntry
+	if err := json.Unmarshal(revision.Snapshot, &snapshot); err != nil {
+		return nil, err
+	}
+	// Tags deleted since the revision can't be linked again
+	if snapshot.Tags != nil {
+		if snapshot.Tags, err = s.tags.FindByIDs(ctx, tagIDs(snapshot.Tags)); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.updateDiary(ctx, id, &snapshot, "diary.restore"); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// resolveTags replaces the tag references of a request with the stored tags. Requests
+// name tags by ID; unknown IDs are a validation error.
+func (s *DiaryService) resolveTags(ctx context.Context, refs []domain.Tag) ([]domain.Tag, error) {
+	if len(refs) == 0 {
+		return refs, nil
+	}
+	ids := tagIDs(refs)
+	tags, err := s.tags.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) != len(ids) {
+		return nil, validation.Errors{"tags": "must reference existing tags by id"}
+	}
+	return tags, nil
+}
+
+// tagIDs returns the distinct IDs of tags.
+func tagIDs(tags []domain.Tag) []uint {
+	ids := make([]uint, 0, len(tags))
+	for _, tag := range tags {
+		if !slices.Contains(ids, tag.ID) {
+			ids = append(ids, tag.ID)
+		}
+	}
+	return ids
+}
+
+func publishEvent(ctx context.Context, entry *domain.DiaryEntry) {
+	events.Publish(ctx, events.Event{Name: domain.EventPublished, Payload: *entry})
 }
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"backend/internal/core/db"
+	"backend/internal/core/validation"
+	"backend/internal/modules/diary/domain"
+	"backend/internal/modules/diary/repository"
+	revisionRepository "backend/internal/modules/revision/repository"
+	revisionService "backend/internal/modules/revision/service"
+
+	"gorm.io/gorm"
+)
+
+type diaryFixture struct {
+	svc     *DiaryService
+	tags    *TagService
+	entries *repository.MemoryDiaryRepository
+}
+
+func newDiaryFixture() *diaryFixture {
+	entries := repository.NewMemoryDiaryRepository()
+	tags := repository.NewMemoryTagRepository(entries)
+	revisions := revisionRepository.NewMemoryRevisionRepository()
+	tx := db.NewMemoryTransactor(entries, revisions)
+	return &diaryFixture{
+		svc:     NewDiaryService(entries, tags, revisionService.NewRevisionService(revisions, tx), tx),
+		tags:    NewTagService(tags),
+		entries: entries,
+	}
+}
+
+func TestPublishDueKeepsTagsInRevision(t *testing.T) {
+	f := newDiaryFixture()
+	ctx := context.Background()
+
+	tag := &domain.Tag{Name: "Travel"}
+	if err := f.tags.CreateTag(ctx, tag); err != nil {
+		t.Fatal(err)
+	}
+	publishAt := time.Now().Add(time.Hour)
+	entry := &domain.DiaryEntry{
+		Slug:      "trip",
+		Title:     "Trip",
+		Status:    domain.StatusScheduled,
+		PublishAt: &publishAt,
+		Tags:      []domain.Tag{{ID: tag.ID}},
+	}
+	if err := f.svc.CreateDiary(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+
+	// Move the publish time into the past, as if the hour had gone by
+	stored, err := f.entries.FindByID(ctx, entry.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	due := time.Now().Add(-time.Minute)
+	stored.PublishAt = &due
+	if err := f.entries.Update(ctx, stored); err != nil {
+		t.Fatal(err)
+	}
+
+	published, err := f.svc.PublishDue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if published != 1 {
+		t.Fatalf("expected one entry to be published, got %d", published)
+	}
+
+	revisions, err := f.svc.GetRevisions(ctx, entry.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest, err := f.svc.GetRevision(ctx, entry.ID, revisions[0].Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snapshot domain.DiaryEntry
+	if err := json.Unmarshal(latest.Snapshot, &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Status != domain.StatusPublished || len(snapshot.Tags) != 1 || snapshot.Tags[0].ID != tag.ID {
+		t.Fatalf("expected the published revision to keep its tag, got %+v", snapshot)
+	}
+
+	restored, err := f.svc.RestoreRevision(ctx, entry.ID, latest.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored.Tags) != 1 {
+		t.Fatalf("expected restoring to keep the tag, got %+v", restored.Tags)
+	}
+}
+
+func TestDeleteDiaryReportsMissingEntry(t *testing.T) {
+	f := newDiaryFixture()
+
+	if err := f.svc.DeleteDiary(context.Background(), 42); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected gorm.ErrRecordNotFound, got %v", err)
+	}
+}
+
+// racingTagRepository lets another request take the slug between the check and the insert.
+type racingTagRepository struct {
+	*repository.MemoryTagRepository
+	races int
+}
+
+func (r *racingTagRepository) Create(ctx context.Context, tag *domain.Tag) error {
+	if r.races > 0 {
+		r.races--
+		rival := &domain.Tag{Name: tag.Name, Slug: tag.Slug}
+		if err := r.MemoryTagRepository.Create(ctx, rival); err != nil {
+			return err
+		}
+	}
+	return r.MemoryTagRepository.Create(ctx, tag)
+}
+
+func TestCreateTagRetriesTakenGeneratedSlug(t *testing.T) {
+	tags := &racingTagRepository{MemoryTagRepository: repository.NewMemoryTagRepository(repository.NewMemoryDiaryRepository()), races: 1}
+	svc := NewTagService(tags)
+
+	tag := &domain.Tag{Name: "Go"}
+	if err := svc.CreateTag(context.Background(), tag); err != nil {
+		t.Fatal(err)
+	}
+	if tag.Slug != "go-2" {
+		t.Fatalf("expected the next free slug, got %q", tag.Slug)
+	}
+}
+
+func TestCreateTagReportsTakenRequestedSlug(t *testing.T) {
+	tags := &racingTagRepository{MemoryTagRepository: repository.NewMemoryTagRepository(repository.NewMemoryDiaryRepository()), races: 1}
+	svc := NewTagService(tags)
+
+	err := svc.CreateTag(context.Background(), &domain.Tag{Name: "Go", Slug: "golang"})
+	errs, ok := validation.As(err)
+	if !ok || errs["slug"] == "" {
+		t.Fatalf("expected a slug validation error, got %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"backend/internal/core/audit"
+	"backend/internal/core/utils"
+	"backend/internal/core/validation"
+	"backend/internal/modules/diary/domain"
+	"backend/internal/modules/diary/port"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// How often CreateTag tries a generated slug, another request can take the free slug
+// prepare found before the insert
+const tagSlugAttempts = 3
+
+type TagService struct {
+	repo port.TagRepository
+}
+
+func NewTagService(repo port.TagRepository) *TagService {
+	return &TagService{repo: repo}
+}
+
+// GetTags lists the tags with the number of public entries of each. Tags without public
+// entries are left out unless includeUnused is set, so drafts don't reveal their topics.
+func (s *TagService) GetTags(ctx context.Context, includeUnused bool) ([]domain.TagCount, error) {
+	tags, err := s.repo.FindAllWithCounts(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if includeUnused {
+		return tags, nil
+	}
+
+	used := []domain.TagCount{}
+	for _, tag := range tags {
+		if tag.Count > 0 {
+			used = append(used, tag)
+		}
+	}
+	return used, nil
+}
+
+// CreateTag stores a new tag. Without a slug one is generated from the name.
+func (s *TagService) CreateTag(ctx context.Context, tag *domain.Tag) error {
+	requested := tag.Slug
+	for attempt := 1; ; attempt++ {
+		if err := s.prepare(ctx, tag, requested, 0); err != nil {
+			return err
+		}
+		err := s.repo.Create(ctx, tag)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return err
+		}
+		if requested != "" || attempt == tagSlugAttempts {
+			return validation.Errors{"slug": "is already taken"}
+		}
+	}
+
+	audit.Record(ctx, audit.Event{
+		Action:     "tag.create",
+		TargetType: "tag",
+		TargetID:   audit.ID(tag.ID),
+		Changes:    audit.Diff(nil, tag),
+	})
+	return nil
+}
+
+// UpdateTag saves the name, description and slug of input over the tag. Renaming keeps
+// the slug, so links to the tag keep working, unless input sets a new one.
+func (s *TagService) UpdateTag(ctx context.Context, id uint, input *domain.Tag) error {
+	tag, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	before := *tag
+
+	slug := input.Slug
+	if slug == "" {
+		slug = tag.Slug
+	}
+	tag.Name = input.Name
+	tag.Description = input.Description
+	if err := s.prepare(ctx, tag, slug, tag.ID); err != nil {
+		return err
+	}
+	if err := s.repo.Update(ctx, tag); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return validation.Errors{"slug": "is already taken"}
+		}
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{
+		Action:     "tag.update",
+		TargetType: "tag",
+		TargetID:   audit.ID(tag.ID),
+		Changes:    audit.Diff(&before, tag),
+	})
+	*input = *tag
+	return nil
+}
+
+func (s *TagService) DeleteTag(ctx context.Context, id uint) error {
+	before, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{
+		Action:     "tag.delete",
+		TargetType: "tag",
+		TargetID:   audit.ID(id),
+		Changes:    audit.Diff(before, nil),
+	})
+	return nil
+}
+
+// prepare validates the tag and sets its slug. A requested slug must be free; a
+// generated one gets a numeric suffix until it is, e.g. "go-2".
+func (s *TagService) prepare(ctx context.Context, tag *domain.Tag, slug string, id uint) error {
+	tag.Name = strings.TrimSpace(tag.Name)
+	if tag.Name == "" {
+		return validation.Errors{"name": "is required"}
+	}
+
+	if slug != "" {
+		tag.Slug = utils.Slugify(slug)
+		if tag.Slug == "" {
+			return validation.Errors{"slug": "must contain letters or digits"}
+		}
+		taken, err := s.repo.SlugExists(ctx, tag.Slug, id)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return validation.Errors{"slug": "is already taken"}
+		}
+		return nil
+	}
+
+	base := utils.Slugify(tag.Name)
+	if base == "" {
+		base = "tag"
+	}
+	for n := 1; ; n++ {
+		tag.Slug = base
+		if n > 1 {
+			tag.Slug += "-" + strconv.Itoa(n)
+		}
+		taken, err := s.repo.SlugExists(ctx, tag.Slug, id)
+		if err != nil {
+			return err
+		}
+		if !taken {
+			return nil
+		}
+	}
+}
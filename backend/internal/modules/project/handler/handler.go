@@ -2,14 +2,21 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"backend/internal/core/db"
+	"backend/internal/core/listing"
+	"backend/internal/core/validation"
 	"backend/internal/modules/project/domain"
 	"backend/internal/modules/project/repository"
 	"backend/internal/modules/project/service"
+	revisionRepo "backend/internal/modules/revision/repository"
+	revisionService "backend/internal/modules/revision/service"
 
 	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
 )
 
 type ProjectHandler struct {
@@ -18,16 +25,36 @@ type ProjectHandler struct {
 
 func NewProjectHandler() *ProjectHandler {
 	repo := repository.NewPostgresProjectRepository()
-	svc := service.NewProjectService(repo)
+	tx := db.NewPostgresTransactor()
+	revisions := revisionService.NewRevisionService(revisionRepo.NewPostgresRevisionRepository(), tx)
+	svc := service.NewProjectService(repo, revisions, tx)
 	return &ProjectHandler{svc: svc}
 }
 
+// Query parameters of GetProjects, see package listing
+var listSpec = listing.Spec{
+	Sorts:       []string{"created_at", "updated_at", "title"},
+	DefaultSort: "-created_at",
+	Filters: []listing.Filter{
+		{Param: "technology", Column: "technologies", Kind: listing.FilterContains},
+		{Param: "role", Column: "role"},
+	},
+	DateColumn: "created_at",
+}
+
 func (h *ProjectHandler) GetProjects(c context.Context, ctx *app.RequestContext) {
-	projects, err := h.svc.GetAllProjects(c)
+	params, err := listing.FromRequest(ctx, listSpec)
+	if errs, ok := validation.As(err); ok {
+		ctx.JSON(http.StatusBadRequest, errs.Response())
+		return
+	}
+
+	projects, total, err := h.svc.GetAllProjects(c, params)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
+	listing.SetHeaders(ctx, params, total)
 	ctx.JSON(http.StatusOK, projects)
 }
 
@@ -84,6 +111,10 @@ func (h *ProjectHandler) DeleteProject(c context.Context, ctx *app.RequestContex
 		return
 	}
 	if err := h.svc.DeleteProject(c, uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
@@ -1,13 +1,15 @@
 package port
 
 import (
+	"backend/internal/core/listing"
 	"backend/internal/modules/project/domain"
 	"context"
 )
 
 type ProjectRepository interface {
 	Create(ctx context.Context, project *domain.Project) error
-	FindAll(ctx context.Context) ([]domain.Project, error)
+	// FindAll returns a page of projects and the total number of matches.
+	FindAll(ctx context.Context, params listing.Params) ([]domain.Project, int64, error)
 	FindBySlug(ctx context.Context, slug string) (*domain.Project, error)
 	FindByID(ctx context.Context, id uint) (*domain.Project, error)
 	Update(ctx context.Context, project *domain.Project) error
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"backend/internal/core/listing"
+	"backend/internal/modules/project/domain"
+	"backend/internal/modules/project/port"
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryProjectRepository keeps projects in a map, for tests and tools that run without
+// a database. Like the Postgres repository it hands out copies, never the stored values.
+type MemoryProjectRepository struct {
+	mu       sync.RWMutex
+	projects map[uint]domain.Project
+	nextID   uint
+}
+
+var _ port.ProjectRepository = (*MemoryProjectRepository)(nil)
+
+func NewMemoryProjectRepository() *MemoryProjectRepository {
+	return &MemoryProjectRepository{projects: make(map[uint]domain.Project)}
+}
+
+func (r *MemoryProjectRepository) Create(ctx context.Context, project *domain.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.slugTaken(project.Slug, 0) {
+		return gorm.ErrDuplicatedKey
+	}
+
+	r.nextID++
+	now := time.Now()
+	project.ID = r.nextID
+	project.CreatedAt, project.UpdatedAt = now, now
+	r.projects[project.ID] = cloneProject(*project)
+	return nil
+}
+
+func (r *MemoryProjectRepository) FindAll(ctx context.Context, params listing.Params) ([]domain.Project, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var projects []domain.Project
+	for _, project := range r.projects {
+		projects = append(projects, cloneProject(project))
+	}
+	page, total := listing.Slice(projects, params)
+	return page, total, nil
+}
+
+func (r *MemoryProjectRepository) FindBySlug(ctx context.Context, slug string) (*domain.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, project := range r.projects {
+		if project.Slug == slug {
+			project = cloneProject(project)
+			return &project, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryProjectRepository) FindByID(ctx context.Context, id uint) (*domain.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	project, ok := r.projects[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	project = cloneProject(project)
+	return &project, nil
+}
+
+func (r *MemoryProjectRepository) Update(ctx context.Context, project *domain.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.projects[project.ID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	if r.slugTaken(project.Slug, project.ID) {
+		return gorm.ErrDuplicatedKey
+	}
+
+	project.UpdatedAt = time.Now()
+	r.projects[project.ID] = cloneProject(*project)
+	return nil
+}
+
+func (r *MemoryProjectRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.projects, id)
+	return nil
+}
+
+func (r *MemoryProjectRepository) slugTaken(slug string, exceptID uint) bool {
+	for id, project := range r.projects {
+		if id != exceptID && project.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// cloneProject copies the array fields too, so callers can't change stored projects.
+func cloneProject(project domain.Project) domain.Project {
+	project.Technologies = slices.Clone(project.Technologies)
+	project.Gallery = slices.Clone(project.Gallery)
+	project.Links = slices.Clone(project.Links)
+	return project
+}
+
+// Snapshot implements db.Snapshotter, so a MemoryTransactor can undo writes made in a
+// transaction that fails.
+func (r *MemoryProjectRepository) Snapshot() func() {
+	r.mu.RLock()
+	projects, nextID := maps.Clone(r.projects), r.nextID
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		r.projects, r.nextID = projects, nextID
+		r.mu.Unlock()
+	}
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"backend/internal/core/db"
+	"backend/internal/core/listing"
 	"backend/internal/modules/project/domain"
 	"backend/internal/modules/project/port"
 	"context"
@@ -16,31 +17,31 @@ func NewPostgresProjectRepository() *PostgresProjectRepository {
 }
 
 func (r *PostgresProjectRepository) Create(ctx context.Context, project *domain.Project) error {
-	return db.DB.WithContext(ctx).Create(project).Error
+	return db.Conn(ctx).Create(project).Error
 }
 
-func (r *PostgresProjectRepository) FindAll(ctx context.Context) ([]domain.Project, error) {
+func (r *PostgresProjectRepository) FindAll(ctx context.Context, params listing.Params) ([]domain.Project, int64, error) {
 	var projects []domain.Project
-	err := db.DB.WithContext(ctx).Order("created_at desc").Find(&projects).Error
-	return projects, err
+	total, err := listing.Find(db.Conn(ctx), params, &projects)
+	return projects, total, err
 }
 
 func (r *PostgresProjectRepository) FindBySlug(ctx context.Context, slug string) (*domain.Project, error) {
 	var project domain.Project
-	err := db.DB.WithContext(ctx).Where("slug = ?", slug).First(&project).Error
+	err := db.Conn(ctx).Where("slug = ?", slug).First(&project).Error
 	return &project, err
 }
 
 func (r *PostgresProjectRepository) FindByID(ctx context.Context, id uint) (*domain.Project, error) {
 	var project domain.Project
-	err := db.DB.WithContext(ctx).First(&project, id).Error
+	err := db.Conn(ctx).First(&project, id).Error
 	return &project, err
 }
 
 func (r *PostgresProjectRepository) Update(ctx context.Context, project *domain.Project) error {
-	return db.DB.WithContext(ctx).Save(project).Error
+	return db.Conn(ctx).Save(project).Error
 }
 
 func (r *PostgresProjectRepository) Delete(ctx context.Context, id uint) error {
-	return db.DB.WithContext(ctx).Delete(&domain.Project{}, id).Error
+	return db.Conn(ctx).Delete(&domain.Project{}, id).Error
 }
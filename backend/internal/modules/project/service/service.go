@@ -2,29 +2,56 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
+	"backend/internal/core/audit"
+	"backend/internal/core/db"
+	"backend/internal/core/listing"
 	"backend/internal/modules/project/domain"
 	"backend/internal/modules/project/port"
+	revisionDomain "backend/internal/modules/revision/domain"
+	revisionService "backend/internal/modules/revision/service"
 )
 
+// Entity type of projects in the revision history
+const revisionType = "project"
+
 type ProjectService struct {
-	repo port.ProjectRepository
+	repo      port.ProjectRepository
+	revisions *revisionService.RevisionService
+	tx        db.Transactor
 }
 
-func NewProjectService(repo port.ProjectRepository) *ProjectService {
-	return &ProjectService{repo: repo}
+func NewProjectService(repo port.ProjectRepository, revisions *revisionService.RevisionService, tx db.Transactor) *ProjectService {
+	return &ProjectService{repo: repo, revisions: revisions, tx: tx}
 }
 
 func (s *ProjectService) CreateProject(ctx context.Context, project *domain.Project) error {
 	if project.CreatedAt.IsZero() {
 		project.CreatedAt = time.Now()
 	}
-	return s.repo.Create(ctx, project)
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, project); err != nil {
+			return err
+		}
+		return s.revisions.Record(ctx, revisionType, project.ID, nil, project)
+	})
+	if err != nil {
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{
+		Action:     "project.create",
+		TargetType: "project",
+		TargetID:   audit.ID(project.ID),
+		Changes:    audit.Diff(nil, project),
+	})
+	return nil
 }
 
-func (s *ProjectService) GetAllProjects(ctx context.Context) ([]domain.Project, error) {
-	return s.repo.FindAll(ctx)
+func (s *ProjectService) GetAllProjects(ctx context.Context, params listing.Params) ([]domain.Project, int64, error) {
+	return s.repo.FindAll(ctx, params)
 }
 
 func (s *ProjectService) GetProjectBySlug(ctx context.Context, slug string) (*domain.Project, error) {
@@ -32,27 +59,107 @@ func (s *ProjectService) GetProjectBySlug(ctx context.Context, slug string) (*do
 }
 
 func (s *ProjectService) UpdateProject(ctx context.Context, id uint, input *domain.Project) error {
-	project, err := s.repo.FindByID(ctx, id)
+	return s.updateProject(ctx, id, input, "project.update")
+}
+
+// updateProject saves the editable fields of input over the project and stores the
+// result as a new revision, so no edit can destroy earlier content.
+func (s *ProjectService) updateProject(ctx context.Context, id uint, input *domain.Project, action string) error {
+	var project *domain.Project
+	var before domain.Project
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if project, err = s.repo.FindByID(ctx, id); err != nil {
+			return err
+		}
+		before = *project
+
+		// Update fields
+		project.Title = input.Title
+		project.Slug = input.Slug
+		project.Description = input.Description
+		project.ImgSrc = input.ImgSrc
+		project.Role = input.Role
+		project.Technologies = input.Technologies
+		project.Overview = input.Overview
+		project.Outcomes = input.Outcomes
+		project.Gallery = input.Gallery
+		project.Links = input.Links
+		// CreatedAt is not updated
+
+		if err := s.repo.Update(ctx, project); err != nil {
+			return err
+		}
+		return s.revisions.Record(ctx, revisionType, project.ID, &before, project)
+	})
 	if err != nil {
 		return err
 	}
 
-	// Update fields
-	project.Title = input.Title
-	project.Slug = input.Slug
-	project.Description = input.Description
-	project.ImgSrc = input.ImgSrc
-	project.Role = input.Role
-	project.Technologies = input.Technologies
-	project.Overview = input.Overview
-	project.Outcomes = input.Outcomes
-	project.Gallery = input.Gallery
-	project.Links = input.Links
-	// CreatedAt is not updated
-
-	return s.repo.Update(ctx, project)
+	audit.Record(ctx, audit.Event{
+		Action:     action,
+		TargetType: "project",
+		TargetID:   audit.ID(project.ID),
+		Changes:    audit.Diff(&before, project),
+	})
+	return nil
 }
 
 func (s *ProjectService) DeleteProject(ctx context.Context, id uint) error {
-	return s.repo.Delete(ctx, id)
+	before, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{
+		Action:     "project.delete",
+		TargetType: "project",
+		TargetID:   audit.ID(id),
+		Changes:    audit.Diff(before, nil),
+	})
+	return nil
+}
+
+// GetRevisions lists the revisions of a project, newest first.
+func (s *ProjectService) GetRevisions(ctx context.Context, id uint) ([]revisionDomain.Revision, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.revisions.List(ctx, revisionType, id)
+}
+
+func (s *ProjectService) GetRevision(ctx context.Context, id uint, version int) (*revisionDomain.Revision, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.revisions.Get(ctx, revisionType, id, version)
+}
+
+// DiffRevisions returns the fields that changed between two revisions of a project.
+func (s *ProjectService) DiffRevisions(ctx context.Context, id uint, from, to int) (map[string]audit.Change, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.revisions.Diff(ctx, revisionType, id, from, to)
+}
+
+// RestoreRevision makes a revision the current version of the project. The restore is
+// itself stored as a new revision, so it can be undone the same way.
+func (s *ProjectService) RestoreRevision(ctx context.Context, id uint, version int) (*domain.Project, error) {
+	revision, err := s.GetRevision(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot domain.Project
+	if err := json.Unmarshal(revision.Snapshot, &snapshot); err != nil {
+		return nil, err
+	}
+	if err := s.updateProject(ctx, id, &snapshot, "project.restore"); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(ctx, id)
 }
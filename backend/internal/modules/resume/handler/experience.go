@@ -2,14 +2,18 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"backend/internal/core/listing"
+	"backend/internal/core/validation"
 	"backend/internal/modules/resume/domain"
 	"backend/internal/modules/resume/repository"
 	"backend/internal/modules/resume/service"
 
 	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
 )
 
 type ExperienceHandler struct {
@@ -22,12 +26,27 @@ func NewExperienceHandler() *ExperienceHandler {
 	return &ExperienceHandler{svc: svc}
 }
 
+// Query parameters of GetExperiences, see package listing
+var experienceListSpec = listing.Spec{
+	Sorts:       []string{"start_date", "end_date", "title", "company"},
+	DefaultSort: "-start_date",
+	Filters:     []listing.Filter{{Param: "company", Column: "company"}},
+	DateColumn:  "start_date",
+}
+
 func (h *ExperienceHandler) GetExperiences(c context.Context, ctx *app.RequestContext) {
-	exps, err := h.svc.GetAllExperiences(c)
+	params, err := listing.FromRequest(ctx, experienceListSpec)
+	if errs, ok := validation.As(err); ok {
+		ctx.JSON(http.StatusBadRequest, errs.Response())
+		return
+	}
+
+	exps, total, err := h.svc.GetAllExperiences(c, params)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
+	listing.SetHeaders(ctx, params, total)
 	ctx.JSON(http.StatusOK, exps)
 }
 
@@ -73,6 +92,10 @@ func (h *ExperienceHandler) DeleteExperience(c context.Context, ctx *app.Request
 		return
 	}
 	if err := h.svc.DeleteExperience(c, uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, map[string]string{"error": "Experience not found"})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
@@ -2,14 +2,18 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"backend/internal/core/listing"
+	"backend/internal/core/validation"
 	"backend/internal/modules/resume/domain"
 	"backend/internal/modules/resume/repository"
 	"backend/internal/modules/resume/service"
 
 	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
 )
 
 type SkillHandler struct {
@@ -22,12 +26,26 @@ func NewSkillHandler() *SkillHandler {
 	return &SkillHandler{svc: svc}
 }
 
+// Query parameters of GetSkills, see package listing. Skills keep their creation order
+// unless sorted.
+var skillListSpec = listing.Spec{
+	Sorts:   []string{"category", "created_at"},
+	Filters: []listing.Filter{{Param: "category", Column: "category"}},
+}
+
 func (h *SkillHandler) GetSkills(c context.Context, ctx *app.RequestContext) {
-	skills, err := h.svc.GetAllSkills(c)
+	params, err := listing.FromRequest(ctx, skillListSpec)
+	if errs, ok := validation.As(err); ok {
+		ctx.JSON(http.StatusBadRequest, errs.Response())
+		return
+	}
+
+	skills, total, err := h.svc.GetAllSkills(c, params)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
+	listing.SetHeaders(ctx, params, total)
 	ctx.JSON(http.StatusOK, skills)
 }
 
@@ -73,6 +91,10 @@ func (h *SkillHandler) DeleteSkill(c context.Context, ctx *app.RequestContext) {
 		return
 	}
 	if err := h.svc.DeleteSkill(c, uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, map[string]string{"error": "Skill not found"})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
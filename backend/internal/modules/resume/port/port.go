@@ -1,13 +1,15 @@
 package port
 
 import (
+	"backend/internal/core/listing"
 	"backend/internal/modules/resume/domain"
 	"context"
 )
 
 type ExperienceRepository interface {
 	Create(ctx context.Context, exp *domain.Experience) error
-	FindAll(ctx context.Context) ([]domain.Experience, error)
+	// FindAll returns a page of experiences and the total number of matches.
+	FindAll(ctx context.Context, params listing.Params) ([]domain.Experience, int64, error)
 	FindByID(ctx context.Context, id uint) (*domain.Experience, error)
 	Update(ctx context.Context, exp *domain.Experience) error
 	Delete(ctx context.Context, id uint) error
@@ -15,7 +17,8 @@ type ExperienceRepository interface {
 
 type SkillRepository interface {
 	Create(ctx context.Context, skill *domain.Skill) error
-	FindAll(ctx context.Context) ([]domain.Skill, error)
+	// FindAll returns a page of skills and the total number of matches.
+	FindAll(ctx context.Context, params listing.Params) ([]domain.Skill, int64, error)
 	FindByID(ctx context.Context, id uint) (*domain.Skill, error)
 	Update(ctx context.Context, skill *domain.Skill) error
 	Delete(ctx context.Context, id uint) error
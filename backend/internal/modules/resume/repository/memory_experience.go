@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"backend/internal/core/listing"
+	"backend/internal/modules/resume/domain"
+	"backend/internal/modules/resume/port"
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryExperienceRepository keeps experiences in a map, for tests and tools that run
+// without a database.
+type MemoryExperienceRepository struct {
+	mu          sync.RWMutex
+	experiences map[uint]domain.Experience
+	nextID      uint
+}
+
+var _ port.ExperienceRepository = (*MemoryExperienceRepository)(nil)
+
+func NewMemoryExperienceRepository() *MemoryExperienceRepository {
+	return &MemoryExperienceRepository{experiences: make(map[uint]domain.Experience)}
+}
+
+func (r *MemoryExperienceRepository) Create(ctx context.Context, exp *domain.Experience) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	now := time.Now()
+	exp.ID = r.nextID
+	exp.CreatedAt, exp.UpdatedAt = now, now
+	r.experiences[exp.ID] = *exp
+	return nil
+}
+
+func (r *MemoryExperienceRepository) FindAll(ctx context.Context, params listing.Params) ([]domain.Experience, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	experiences := make([]domain.Experience, 0, len(r.experiences))
+	for _, exp := range r.experiences {
+		experiences = append(experiences, exp)
+	}
+	page, total := listing.Slice(experiences, params)
+	return page, total, nil
+}
+
+func (r *MemoryExperienceRepository) FindByID(ctx context.Context, id uint) (*domain.Experience, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	exp, ok := r.experiences[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &exp, nil
+}
+
+func (r *MemoryExperienceRepository) Update(ctx context.Context, exp *domain.Experience) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.experiences[exp.ID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	exp.UpdatedAt = time.Now()
+	r.experiences[exp.ID] = *exp
+	return nil
+}
+
+func (r *MemoryExperienceRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.experiences, id)
+	return nil
+}
+
+// Snapshot implements db.Snapshotter, so a MemoryTransactor can undo writes made in a
+// transaction that fails.
+func (r *MemoryExperienceRepository) Snapshot() func() {
+	r.mu.RLock()
+	experiences, nextID := maps.Clone(r.experiences), r.nextID
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		r.experiences, r.nextID = experiences, nextID
+		r.mu.Unlock()
+	}
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"backend/internal/core/listing"
+	"backend/internal/modules/resume/domain"
+	"backend/internal/modules/resume/port"
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemorySkillRepository keeps skills in a map, for tests and tools that run without a
+// database.
+type MemorySkillRepository struct {
+	mu     sync.RWMutex
+	skills map[uint]domain.Skill
+	nextID uint
+}
+
+var _ port.SkillRepository = (*MemorySkillRepository)(nil)
+
+func NewMemorySkillRepository() *MemorySkillRepository {
+	return &MemorySkillRepository{skills: make(map[uint]domain.Skill)}
+}
+
+func (r *MemorySkillRepository) Create(ctx context.Context, skill *domain.Skill) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	now := time.Now()
+	skill.ID = r.nextID
+	skill.CreatedAt, skill.UpdatedAt = now, now
+	r.skills[skill.ID] = cloneSkill(*skill)
+	return nil
+}
+
+func (r *MemorySkillRepository) FindAll(ctx context.Context, params listing.Params) ([]domain.Skill, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	skills := make([]domain.Skill, 0, len(r.skills))
+	for _, skill := range r.skills {
+		skills = append(skills, cloneSkill(skill))
+	}
+	sort.Slice(skills, func(i, j int) bool { return skills[i].ID < skills[j].ID })
+	page, total := listing.Slice(skills, params)
+	return page, total, nil
+}
+
+func (r *MemorySkillRepository) FindByID(ctx context.Context, id uint) (*domain.Skill, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	skill, ok := r.skills[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	skill = cloneSkill(skill)
+	return &skill, nil
+}
+
+func (r *MemorySkillRepository) Update(ctx context.Context, skill *domain.Skill) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.skills[skill.ID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	skill.UpdatedAt = time.Now()
+	r.skills[skill.ID] = cloneSkill(*skill)
+	return nil
+}
+
+func (r *MemorySkillRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.skills, id)
+	return nil
+}
+
+func cloneSkill(skill domain.Skill) domain.Skill {
+	skill.Items = slices.Clone(skill.Items)
+	return skill
+}
+
+// Snapshot implements db.Snapshotter, so a MemoryTransactor can undo writes made in a
+// transaction that fails.
+func (r *MemorySkillRepository) Snapshot() func() {
+	r.mu.RLock()
+	skills, nextID := maps.Clone(r.skills), r.nextID
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		r.skills, r.nextID = skills, nextID
+		r.mu.Unlock()
+	}
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"backend/internal/core/db"
+	"backend/internal/core/listing"
 	"backend/internal/modules/resume/domain"
 	"backend/internal/modules/resume/port"
 	"context"
@@ -16,29 +17,30 @@ func NewPostgresExperienceRepository() *PostgresExperienceRepository {
 }
 
 func (r *PostgresExperienceRepository) Create(ctx context.Context, exp *domain.Experience) error {
-	return db.DB.WithContext(ctx).Create(exp).Error
+	return db.Conn(ctx).Create(exp).Error
 }
 
-func (r *PostgresExperienceRepository) FindAll(ctx context.Context) ([]domain.Experience, error) {
+func (r *PostgresExperienceRepository) FindAll(ctx context.Context, params listing.Params) ([]domain.Experience, int64, error) {
 	var experiences []domain.Experience
-	if err := db.DB.WithContext(ctx).Order("start_date desc").Find(&experiences).Error; err != nil {
-		return nil, err
+	total, err := listing.Find(db.Conn(ctx), params, &experiences)
+	if err != nil {
+		return nil, 0, err
 	}
-	return experiences, nil
+	return experiences, total, nil
 }
 
 func (r *PostgresExperienceRepository) FindByID(ctx context.Context, id uint) (*domain.Experience, error) {
 	var exp domain.Experience
-	if err := db.DB.WithContext(ctx).First(&exp, id).Error; err != nil {
+	if err := db.Conn(ctx).First(&exp, id).Error; err != nil {
 		return nil, err
 	}
 	return &exp, nil
 }
 
 func (r *PostgresExperienceRepository) Update(ctx context.Context, exp *domain.Experience) error {
-	return db.DB.WithContext(ctx).Save(exp).Error
+	return db.Conn(ctx).Save(exp).Error
 }
 
 func (r *PostgresExperienceRepository) Delete(ctx context.Context, id uint) error {
-	return db.DB.WithContext(ctx).Delete(&domain.Experience{}, id).Error
+	return db.Conn(ctx).Delete(&domain.Experience{}, id).Error
 }
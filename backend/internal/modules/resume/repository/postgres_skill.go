@@ -2,6 +2,7 @@ package repository
 
 import (
 	"backend/internal/core/db"
+	"backend/internal/core/listing"
 	"backend/internal/modules/resume/domain"
 	"backend/internal/modules/resume/port"
 	"context"
@@ -16,29 +17,30 @@ func NewPostgresSkillRepository() *PostgresSkillRepository {
 }
 
 func (r *PostgresSkillRepository) Create(ctx context.Context, skill *domain.Skill) error {
-	return db.DB.WithContext(ctx).Create(skill).Error
+	return db.Conn(ctx).Create(skill).Error
 }
 
-func (r *PostgresSkillRepository) FindAll(ctx context.Context) ([]domain.Skill, error) {
+func (r *PostgresSkillRepository) FindAll(ctx context.Context, params listing.Params) ([]domain.Skill, int64, error) {
 	var skills []domain.Skill
-	if err := db.DB.WithContext(ctx).Find(&skills).Error; err != nil {
-		return nil, err
+	total, err := listing.Find(db.Conn(ctx), params, &skills)
+	if err != nil {
+		return nil, 0, err
 	}
-	return skills, nil
+	return skills, total, nil
 }
 
 func (r *PostgresSkillRepository) FindByID(ctx context.Context, id uint) (*domain.Skill, error) {
 	var skill domain.Skill
-	if err := db.DB.WithContext(ctx).First(&skill, id).Error; err != nil {
+	if err := db.Conn(ctx).First(&skill, id).Error; err != nil {
 		return nil, err
 	}
 	return &skill, nil
 }
 
 func (r *PostgresSkillRepository) Update(ctx context.Context, skill *domain.Skill) error {
-	return db.DB.WithContext(ctx).Save(skill).Error
+	return db.Conn(ctx).Save(skill).Error
 }
 
 func (r *PostgresSkillRepository) Delete(ctx context.Context, id uint) error {
-	return db.DB.WithContext(ctx).Delete(&domain.Skill{}, id).Error
+	return db.Conn(ctx).Delete(&domain.Skill{}, id).Error
 }
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"backend/internal/core/audit"
+	"backend/internal/core/listing"
 	"backend/internal/modules/resume/domain"
 	"backend/internal/modules/resume/port"
 	"context"
@@ -14,12 +16,22 @@ func NewExperienceService(repo port.ExperienceRepository) *ExperienceService {
 	return &ExperienceService{repo: repo}
 }
 
-func (s *ExperienceService) GetAllExperiences(ctx context.Context) ([]domain.Experience, error) {
-	return s.repo.FindAll(ctx)
+func (s *ExperienceService) GetAllExperiences(ctx context.Context, params listing.Params) ([]domain.Experience, int64, error) {
+	return s.repo.FindAll(ctx, params)
 }
 
 func (s *ExperienceService) CreateExperience(ctx context.Context, exp *domain.Experience) error {
-	return s.repo.Create(ctx, exp)
+	if err := s.repo.Create(ctx, exp); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{
+		Action:     "experience.create",
+		TargetType: "experience",
+		TargetID:   audit.ID(exp.ID),
+		Changes:    audit.Diff(nil, exp),
+	})
+	return nil
 }
 
 func (s *ExperienceService) UpdateExperience(ctx context.Context, id uint, exp *domain.Experience) error {
@@ -28,9 +40,33 @@ func (s *ExperienceService) UpdateExperience(ctx context.Context, id uint, exp *
 		return err
 	}
 	exp.ID = existing.ID
-	return s.repo.Update(ctx, exp)
+	if err := s.repo.Update(ctx, exp); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{
+		Action:     "experience.update",
+		TargetType: "experience",
+		TargetID:   audit.ID(id),
+		Changes:    audit.Diff(existing, exp),
+	})
+	return nil
 }
 
 func (s *ExperienceService) DeleteExperience(ctx context.Context, id uint) error {
-	return s.repo.Delete(ctx, id)
+	before, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{
+		Action:     "experience.delete",
+		TargetType: "experience",
+		TargetID:   audit.ID(id),
+		Changes:    audit.Diff(before, nil),
+	})
+	return nil
 }
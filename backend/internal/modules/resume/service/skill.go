@@ -1,6 +1,8 @@
 package service
 
 import (
+	"backend/internal/core/audit"
+	"backend/internal/core/listing"
 	"backend/internal/modules/resume/domain"
 	"backend/internal/modules/resume/port"
 	"context"
@@ -14,12 +16,22 @@ func NewSkillService(repo port.SkillRepository) *SkillService {
 	return &SkillService{repo: repo}
 }
 
-func (s *SkillService) GetAllSkills(ctx context.Context) ([]domain.Skill, error) {
-	return s.repo.FindAll(ctx)
+func (s *SkillService) GetAllSkills(ctx context.Context, params listing.Params) ([]domain.Skill, int64, error) {
+	return s.repo.FindAll(ctx, params)
 }
 
 func (s *SkillService) CreateSkill(ctx context.Context, skill *domain.Skill) error {
-	return s.repo.Create(ctx, skill)
+	if err := s.repo.Create(ctx, skill); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{
+		Action:     "skill.create",
+		TargetType: "skill",
+		TargetID:   audit.ID(skill.ID),
+		Changes:    audit.Diff(nil, skill),
+	})
+	return nil
 }
 
 func (s *SkillService) UpdateSkill(ctx context.Context, id uint, skill *domain.Skill) error {
@@ -28,9 +40,33 @@ func (s *SkillService) UpdateSkill(ctx context.Context, id uint, skill *domain.S
 		return err
 	}
 	skill.ID = existing.ID
-	return s.repo.Update(ctx, skill)
+	if err := s.repo.Update(ctx, skill); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{
+		Action:     "skill.update",
+		TargetType: "skill",
+		TargetID:   audit.ID(id),
+		Changes:    audit.Diff(existing, skill),
+	})
+	return nil
 }
 
 func (s *SkillService) DeleteSkill(ctx context.Context, id uint) error {
-	return s.repo.Delete(ctx, id)
+	before, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{
+		Action:     "skill.delete",
+		TargetType: "skill",
+		TargetID:   audit.ID(id),
+		Changes:    audit.Diff(before, nil),
+	})
+	return nil
 }
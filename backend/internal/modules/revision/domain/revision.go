@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Revision is an immutable snapshot of a record taken when it was created or updated.
+// Versions count up from 1 per record.
+type Revision struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	EntityType string          `gorm:"uniqueIndex:idx_revision_version;not null;size:32" json:"entity_type"` // e.g. "diary"
+	EntityID   uint            `gorm:"uniqueIndex:idx_revision_version;not null" json:"entity_id"`
+	Version    int             `gorm:"uniqueIndex:idx_revision_version;not null" json:"version"`
+	AuthorID   *uint           `gorm:"index" json:"author_id"`                        // Nil for the baseline of records older than revisions
+	Snapshot   json.RawMessage `gorm:"type:jsonb;not null" json:"snapshot,omitempty"` // JSON form of the whole record
+}
+
+func (Revision) TableName() string {
+	return "revisions"
+}
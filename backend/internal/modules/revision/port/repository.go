@@ -0,0 +1,15 @@
+package port
+
+import (
+	"backend/internal/modules/revision/domain"
+	"context"
+)
+
+type RevisionRepository interface {
+	Create(ctx context.Context, revision *domain.Revision) error
+	// FindByEntity lists the revisions of a record, newest first, without snapshots.
+	FindByEntity(ctx context.Context, entityType string, entityID uint) ([]domain.Revision, error)
+	FindVersion(ctx context.Context, entityType string, entityID uint, version int) (*domain.Revision, error)
+	// LatestVersion returns 0 when the record has no revisions yet.
+	LatestVersion(ctx context.Context, entityType string, entityID uint) (int, error)
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"backend/internal/modules/revision/domain"
+	"backend/internal/modules/revision/port"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryRevisionRepository keeps revisions in a slice, for tests and tools that run
+// without a database.
+type MemoryRevisionRepository struct {
+	mu        sync.RWMutex
+	revisions []domain.Revision
+}
+
+var _ port.RevisionRepository = (*MemoryRevisionRepository)(nil)
+
+func NewMemoryRevisionRepository() *MemoryRevisionRepository {
+	return &MemoryRevisionRepository{}
+}
+
+func (r *MemoryRevisionRepository) Create(ctx context.Context, revision *domain.Revision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, other := range r.revisions {
+		if other.EntityType == revision.EntityType && other.EntityID == revision.EntityID && other.Version == revision.Version {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	revision.ID = uint(len(r.revisions) + 1)
+	revision.CreatedAt = time.Now()
+	r.revisions = append(r.revisions, *revision)
+	return nil
+}
+
+func (r *MemoryRevisionRepository) FindByEntity(ctx context.Context, entityType string, entityID uint) ([]domain.Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Versions are created in order, so walking backwards is newest first
+	var revisions []domain.Revision
+	for i := len(r.revisions) - 1; i >= 0; i-- {
+		revision := r.revisions[i]
+		if revision.EntityType == entityType && revision.EntityID == entityID {
+			revision.Snapshot = nil
+			revisions = append(revisions, revision)
+		}
+	}
+	return revisions, nil
+}
+
+func (r *MemoryRevisionRepository) FindVersion(ctx context.Context, entityType string, entityID uint, version int) (*domain.Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, revision := range r.revisions {
+		if revision.EntityType == entityType && revision.EntityID == entityID && revision.Version == version {
+			return &revision, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryRevisionRepository) LatestVersion(ctx context.Context, entityType string, entityID uint) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	latest := 0
+	for _, revision := range r.revisions {
+		if revision.EntityType == entityType && revision.EntityID == entityID {
+			latest = max(latest, revision.Version)
+		}
+	}
+	return latest, nil
+}
+
+// Snapshot implements db.Snapshotter, so a MemoryTransactor can undo writes made in a
+// transaction that fails.
+func (r *MemoryRevisionRepository) Snapshot() func() {
+	r.mu.RLock()
+	revisions := slices.Clone(r.revisions)
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		r.revisions = revisions
+		r.mu.Unlock()
+	}
+}
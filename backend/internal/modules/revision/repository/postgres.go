@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"backend/internal/core/db"
+	"backend/internal/modules/revision/domain"
+	"backend/internal/modules/revision/port"
+	"context"
+)
+
+type PostgresRevisionRepository struct{}
+
+var _ port.RevisionRepository = (*PostgresRevisionRepository)(nil)
+
+func NewPostgresRevisionRepository() *PostgresRevisionRepository {
+	return &PostgresRevisionRepository{}
+}
+
+func (r *PostgresRevisionRepository) Create(ctx context.Context, revision *domain.Revision) error {
+	return db.Conn(ctx).Create(revision).Error
+}
+
+func (r *PostgresRevisionRepository) FindByEntity(ctx context.Context, entityType string, entityID uint) ([]domain.Revision, error) {
+	var revisions []domain.Revision
+	err := db.Conn(ctx).Omit("snapshot").
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("version desc").
+		Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *PostgresRevisionRepository) FindVersion(ctx context.Context, entityType string, entityID uint, version int) (*domain.Revision, error) {
+	var revision domain.Revision
+	err := db.Conn(ctx).
+		Where("entity_type = ? AND entity_id = ? AND version = ?", entityType, entityID, version).
+		First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+func (r *PostgresRevisionRepository) LatestVersion(ctx context.Context, entityType string, entityID uint) (int, error) {
+	var version int
+	err := db.Conn(ctx).Model(&domain.Revision{}).
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&version).Error
+	return version, err
+}
+
+// EnsureImmutable installs a trigger that rejects UPDATE and DELETE on revisions, the
+// same protection the audit log has.
+func (r *PostgresRevisionRepository) EnsureImmutable(ctx context.Context) error {
+	return db.Conn(ctx).Exec(`
+CREATE OR REPLACE FUNCTION revisions_immutable() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'revisions are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS revisions_immutable ON revisions;
+CREATE TRIGGER revisions_immutable
+	BEFORE UPDATE OR DELETE ON revisions
+	FOR EACH ROW EXECUTE FUNCTION revisions_immutable();
+`).Error
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"hash/fnv"
+
+	"backend/internal/core/audit"
+	"backend/internal/core/db"
+	"backend/internal/modules/revision/domain"
+	"backend/internal/modules/revision/port"
+)
+
+// RevisionService keeps the history of records for the modules that own them. It has no
+// endpoints of its own, owners expose the history under their routes.
+type RevisionService struct {
+	repo port.RevisionRepository
+	tx   db.Transactor
+}
+
+func NewRevisionService(repo port.RevisionRepository, tx db.Transactor) *RevisionService {
+	return &RevisionService{repo: repo, tx: tx}
+}
+
+// Record stores after as the next revision of the record, authored by the actor in
+// ctx. before is the state the change started from; it is stored first as a baseline
+// when the record has no history yet, e.g. because it predates revisions. Call it in
+// the same transaction as the write, so history can't miss a change.
+func (s *RevisionService) Record(ctx context.Context, entityType string, entityID uint, before, after any) error {
+	return s.tx.Transaction(ctx, func(ctx context.Context) error {
+		// Held until the outer transaction ends, so concurrent writers of the same record
+		// number their revisions one after the other
+		if err := s.tx.Lock(ctx, historyLockKey(entityType, entityID)); err != nil {
+			return err
+		}
+
+		latest, err := s.repo.LatestVersion(ctx, entityType, entityID)
+		if err != nil {
+			return err
+		}
+
+		if latest == 0 && before != nil {
+			if err := s.create(ctx, entityType, entityID, 1, nil, before); err != nil {
+				return err
+			}
+			latest = 1
+		}
+		return s.create(ctx, entityType, entityID, latest+1, audit.Actor(ctx), after)
+	})
+}
+
+// historyLockKey derives the advisory lock key guarding the history of one record.
+func historyLockKey(entityType string, entityID uint) int64 {
+	h := fnv.New64a()
+	h.Write(binary.BigEndian.AppendUint64([]byte(entityType+"\x00"), uint64(entityID)))
+	return int64(h.Sum64())
+}
+
+func (s *RevisionService) create(ctx context.Context, entityType string, entityID uint, version int, authorID *uint, snapshot any) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, &domain.Revision{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Version:    version,
+		AuthorID:   authorID,
+		Snapshot:   data,
+	})
+}
+
+// List returns the revisions of a record newest first, without their snapshots.
+func (s *RevisionService) List(ctx context.Context, entityType string, entityID uint) ([]domain.Revision, error) {
+	return s.repo.FindByEntity(ctx, entityType, entityID)
+}
+
+func (s *RevisionService) Get(ctx context.Context, entityType string, entityID uint, version int) (*domain.Revision, error) {
+	return s.repo.FindVersion(ctx, entityType, entityID, version)
+}
+
+// Diff returns the fields that differ between two versions of a record.
+func (s *RevisionService) Diff(ctx context.Context, entityType string, entityID uint, from, to int) (map[string]audit.Change, error) {
+	before, err := s.repo.FindVersion(ctx, entityType, entityID, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.repo.FindVersion(ctx, entityType, entityID, to)
+	if err != nil {
+		return nil, err
+	}
+	return audit.Diff(before.Snapshot, after.Snapshot), nil
+}
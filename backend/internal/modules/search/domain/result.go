@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// Searchable record types, also the values of the ?type= filter
+const (
+	TypeDiary   = "diary"
+	TypeProject = "project"
+)
+
+func IsValidType(t string) bool {
+	return t == TypeDiary || t == TypeProject
+}
+
+// Result is one match, ranked against the others.
+type Result struct {
+	Type    string    `json:"type"`
+	ID      uint      `json:"id"`
+	Slug    string    `json:"slug"`
+	Title   string    `json:"title"`
+	Snippet string    `json:"snippet"` // HTML-escaped text with the matches wrapped in <mark></mark>
+	Rank    float64   `json:"rank"`
+	Date    time.Time `json:"date"` // Entry date of diaries, creation date of projects
+}
+
+// Query is a search request. Text uses web search syntax: "quoted phrases", or, -word.
+type Query struct {
+	Text           string
+	Types          []string // Empty searches all types
+	IncludePrivate bool     // Also match private and unpublished diary entries
+	Now            time.Time
+	Limit          int
+	Offset         int
+}
+
+func (q Query) Includes(t string) bool {
+	if len(q.Types) == 0 {
+		return true
+	}
+	for _, qt := range q.Types {
+		if qt == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"backend/internal/core/listing"
+	"backend/internal/core/server/middleware"
+	"backend/internal/core/validation"
+	"backend/internal/modules/search/repository"
+	"backend/internal/modules/search/service"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type SearchHandler struct {
+	svc *service.SearchService
+}
+
+func NewSearchHandler() *SearchHandler {
+	repo := repository.NewPostgresSearchRepository()
+	svc := service.NewSearchService(repo)
+	return &SearchHandler{svc: svc}
+}
+
+// Results are ordered by relevance, so only paging applies. Unlike the other lists a
+// search always returns a page.
+var listSpec = listing.Spec{DefaultLimit: 20}
+
+// Search handles /api/search?q=, optionally with type=diary,project and the paging
+// parameters of package listing. Callers who can't write diary entries only find
+// public, published ones.
+func (h *SearchHandler) Search(c context.Context, ctx *app.RequestContext) {
+	canSeePrivate := ctx.GetBool(middleware.ContextCanSeePrivate) // Set by OptionalAuth
+
+	params, err := listing.FromRequest(ctx, listSpec)
+	if errs, ok := validation.As(err); ok {
+		ctx.JSON(http.StatusBadRequest, errs.Response())
+		return
+	}
+
+	results, total, err := h.svc.Search(c, ctx.Query("q"), ctx.Query("type"), canSeePrivate, params)
+	if err != nil {
+		if errs, ok := validation.As(err); ok {
+			ctx.JSON(http.StatusBadRequest, errs.Response())
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	listing.SetHeaders(ctx, params, total)
+	ctx.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,11 @@
+package port
+
+import (
+	"backend/internal/modules/search/domain"
+	"context"
+)
+
+type SearchRepository interface {
+	// Search returns a page of results, best match first, and the total number of matches.
+	Search(ctx context.Context, query domain.Query) ([]domain.Result, int64, error)
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"backend/internal/core/listing"
+	diaryPort "backend/internal/modules/diary/port"
+	projectPort "backend/internal/modules/project/port"
+	"backend/internal/modules/search/domain"
+	"backend/internal/modules/search/port"
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MemorySearchRepository searches the records of the diary and project repositories it
+// is given by plain word matching. Every word of the query has to occur, words
+// prefixed with "-" must not; there is no stemming and the rank only counts matches,
+// weighted like the Postgres index.
+type MemorySearchRepository struct {
+	diaries  diaryPort.DiaryRepository
+	projects projectPort.ProjectRepository
+}
+
+var _ port.SearchRepository = (*MemorySearchRepository)(nil)
+
+func NewMemorySearchRepository(diaries diaryPort.DiaryRepository, projects projectPort.ProjectRepository) *MemorySearchRepository {
+	return &MemorySearchRepository{diaries: diaries, projects: projects}
+}
+
+var (
+	htmlTag   = regexp.MustCompile(`<[^>]*>`)
+	everyPage = listing.Params{}
+)
+
+// document is a record split into fields of decreasing weight, like the search_vector columns
+type document struct {
+	result domain.Result
+	fields [3]string
+	body   string
+}
+
+func (r *MemorySearchRepository) Search(ctx context.Context, query domain.Query) ([]domain.Result, int64, error) {
+	var docs []document
+
+	if query.Includes(domain.TypeDiary) {
+		entries, _, err := r.diaries.FindAll(ctx, query.IncludePrivate, "", query.Now, everyPage)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, e := range entries {
+			excerpt, content := stripTags(e.Excerpt), stripTags(e.Content)
+			docs = append(docs, document{
+				result: domain.Result{Type: domain.TypeDiary, ID: e.ID, Slug: e.Slug, Title: e.Title, Date: e.Date},
+				fields: [3]string{e.Title, excerpt, content},
+				body:   excerpt + " " + content,
+			})
+		}
+	}
+
+	if query.Includes(domain.TypeProject) {
+		projects, _, err := r.projects.FindAll(ctx, everyPage)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, p := range projects {
+			description, overview, outcomes := stripTags(p.Description), stripTags(p.Overview), stripTags(p.Outcomes)
+			docs = append(docs, document{
+				result: domain.Result{Type: domain.TypeProject, ID: p.ID, Slug: p.Slug, Title: p.Title, Date: p.CreatedAt},
+				fields: [3]string{p.Title, description + " " + strings.Join(p.Technologies, " "), overview + " " + outcomes},
+				body:   strings.Join([]string{description, overview, outcomes}, " "),
+			})
+		}
+	}
+
+	include, exclude := queryTerms(query.Text)
+	if len(include) == 0 {
+		return []domain.Result{}, 0, nil
+	}
+
+	results := []domain.Result{}
+	for _, doc := range docs {
+		if rank, ok := doc.rank(include, exclude); ok {
+			doc.result.Rank = rank
+			doc.result.Snippet = snippet(doc.body, include)
+			results = append(results, doc.result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Rank != b.Rank {
+			return a.Rank > b.Rank
+		}
+		if !a.Date.Equal(b.Date) {
+			return a.Date.After(b.Date)
+		}
+		return a.ID > b.ID
+	})
+
+	total := int64(len(results))
+	results = results[min(query.Offset, len(results)):]
+	results = results[:min(query.Limit, len(results))]
+	return results, total, nil
+}
+
+var fieldWeights = [3]float64{1.0, 0.4, 0.2}
+
+func (d document) rank(include, exclude []string) (float64, bool) {
+	var words [3][]string
+	for i, field := range d.fields {
+		words[i] = splitWords(field)
+	}
+
+	var rank float64
+	for _, term := range include {
+		found := false
+		for i := range words {
+			for _, w := range words[i] {
+				if w == term {
+					rank += fieldWeights[i]
+					found = true
+				}
+			}
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	for _, term := range exclude {
+		for i := range words {
+			for _, w := range words[i] {
+				if w == term {
+					return 0, false
+				}
+			}
+		}
+	}
+	return rank, true
+}
+
+// queryTerms splits web search syntax into the words that must and must not occur.
+// Quotes and "or" are ignored.
+func queryTerms(text string) (include, exclude []string) {
+	for _, field := range strings.Fields(strings.ToLower(text)) {
+		negate := strings.HasPrefix(field, "-")
+		for _, w := range splitWords(field) {
+			if w == "or" {
+				continue
+			}
+			if negate {
+				exclude = append(exclude, w)
+			} else {
+				include = append(include, w)
+			}
+		}
+	}
+	return include, exclude
+}
+
+func splitWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !(r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r > 127)
+	})
+}
+
+// snippet returns up to 35 words of body starting a little before the first match,
+// HTML-escaped and with the matches wrapped in <mark></mark> like the Postgres snippets.
+func snippet(body string, terms []string) string {
+	words := strings.Fields(body)
+	start := len(words)
+	for i, w := range words {
+		if isMatch(w, terms) {
+			start = i
+			break
+		}
+	}
+	if start == len(words) {
+		start = 0
+	}
+	start = max(0, start-5)
+	end := min(len(words), start+35)
+
+	out := make([]string, 0, end-start)
+	for _, w := range words[start:end] {
+		marked := isMatch(w, terms)
+		w = escapeText(w)
+		if marked {
+			w = "<mark>" + w + "</mark>"
+		}
+		out = append(out, w)
+	}
+	return strings.Join(out, " ")
+}
+
+func stripTags(s string) string {
+	return htmlTag.ReplaceAllString(s, " ")
+}
+
+func isMatch(word string, terms []string) bool {
+	for _, w := range splitWords(word) {
+		for _, term := range terms {
+			if w == term {
+				return true
+			}
+		}
+	}
+	return false
+}
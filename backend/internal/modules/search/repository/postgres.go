@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"backend/internal/core/db"
+	diaryDomain "backend/internal/modules/diary/domain"
+	"backend/internal/modules/search/domain"
+	"backend/internal/modules/search/port"
+	"context"
+	"html"
+	"strings"
+)
+
+type PostgresSearchRepository struct{}
+
+var _ port.SearchRepository = (*PostgresSearchRepository)(nil)
+
+func NewPostgresSearchRepository() *PostgresSearchRepository {
+	return &PostgresSearchRepository{}
+}
+
+// ts_headline marks matches with these private use characters rather than <mark>, so
+// the snippet can be escaped before the tags are put in
+const (
+	markStart = "\ue000"
+	markStop  = "\ue001"
+)
+
+// headlineOptions shape the snippets: up to two fragments with the matches marked
+const headlineOptions = "StartSel=" + markStart + ", StopSel=" + markStop + ", MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=\" … \""
+
+// EnsureSearchIndex adds the generated search_vector columns and their GIN indexes to
+// diary_entries and projects, using the same 'english' configuration as the queries.
+// Postgres keeps the columns up to date on every write.
+// Titles weigh most; every other text field is indexed with its HTML tags stripped.
+func (r *PostgresSearchRepository) EnsureSearchIndex(ctx context.Context) error {
+	return db.Conn(ctx).Exec(`
+-- array_to_string is only STABLE, generated columns need an IMMUTABLE expression
+CREATE OR REPLACE FUNCTION search_array_to_text(text[]) RETURNS text
+	LANGUAGE sql IMMUTABLE PARALLEL SAFE
+	AS $$ SELECT array_to_string($1, ' ') $$;
+
+CREATE OR REPLACE FUNCTION search_strip_tags(text) RETURNS text
+	LANGUAGE sql IMMUTABLE PARALLEL SAFE
+	AS $$ SELECT regexp_replace(coalesce($1, ''), '<[^>]*>', ' ', 'g') $$;
+
+-- Columns generated before every field was stripped are rebuilt, along with their index
+DO $$
+BEGIN
+	IF EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_name = 'diary_entries' AND column_name = 'search_vector'
+			AND generation_expression NOT LIKE '%search_strip_tags%'
+	) THEN
+		ALTER TABLE diary_entries DROP COLUMN search_vector;
+	END IF;
+	IF EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_name = 'projects' AND column_name = 'search_vector'
+			AND generation_expression NOT LIKE '%search_strip_tags%'
+	) THEN
+		ALTER TABLE projects DROP COLUMN search_vector;
+	END IF;
+END $$;
+
+ALTER TABLE diary_entries ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+	setweight(to_tsvector('english', search_strip_tags(excerpt)), 'B') ||
+	setweight(to_tsvector('english', search_strip_tags(content)), 'C')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_diary_entries_search_vector ON diary_entries USING GIN (search_vector);
+
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+	setweight(to_tsvector('english', search_strip_tags(description) || ' ' || coalesce(search_array_to_text(technologies), '')), 'B') ||
+	setweight(to_tsvector('english', search_strip_tags(overview) || ' ' || search_strip_tags(outcomes)), 'C')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_projects_search_vector ON projects USING GIN (search_vector);
+`).Error
+}
+
+func (r *PostgresSearchRepository) Search(ctx context.Context, query domain.Query) ([]domain.Result, int64, error) {
+	matches, args := matchQuery(query)
+	if matches == "" {
+		return []domain.Result{}, 0, nil
+	}
+
+	var total int64
+	if err := db.Conn(ctx).Raw("SELECT count(*) FROM ("+matches+") m", args...).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []domain.Result{}, 0, nil
+	}
+
+	// Headlines are costly, so they are only built for the rows of the page. The body
+	// loses any marker characters of its own, they would turn into stray tags.
+	results := []domain.Result{}
+	args = append(append([]any{markStart + markStop, query.Text, headlineOptions}, args...), query.Limit, query.Offset)
+	err := db.Conn(ctx).Raw(`
+SELECT type, id, slug, title, rank, date,
+	ts_headline('english', translate(body, ?, ''), websearch_to_tsquery('english', ?), ?) AS snippet
+FROM (`+matches+`) m
+ORDER BY rank DESC, date DESC, id DESC
+LIMIT ? OFFSET ?`, args...).Scan(&results).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range results {
+		snippet := escapeText(results[i].Snippet)
+		snippet = strings.ReplaceAll(snippet, markStart, "<mark>")
+		results[i].Snippet = strings.ReplaceAll(snippet, markStop, "</mark>")
+	}
+	return results, total, nil
+}
+
+// escapeText HTML-escapes text that came out of HTML with its tags stripped. Entities
+// are decoded first, so they aren't escaped twice.
+func escapeText(s string) string {
+	return html.EscapeString(html.UnescapeString(s))
+}
+
+// matchQuery builds a UNION of the matching rows of every requested type, with the text
+// snippets are built from in body.
+func matchQuery(query domain.Query) (string, []any) {
+	var parts []string
+	var args []any
+
+	if query.Includes(domain.TypeDiary) {
+		part := `
+SELECT 'diary' AS type, id, slug, title, date,
+	ts_rank_cd(search_vector, websearch_to_tsquery('english', ?)) AS rank,
+	search_strip_tags(excerpt) || ' ' || search_strip_tags(content) AS body
+FROM diary_entries
+WHERE deleted_at IS NULL AND search_vector @@ websearch_to_tsquery('english', ?)`
+		args = append(args, query.Text, query.Text)
+		if !query.IncludePrivate {
+			part += ` AND visibility = ? AND status = ? AND (publish_at IS NULL OR publish_at <= ?)`
+			args = append(args, "public", diaryDomain.StatusPublished, query.Now)
+		}
+		parts = append(parts, part)
+	}
+
+	if query.Includes(domain.TypeProject) {
+		parts = append(parts, `
+SELECT 'project' AS type, id, slug, title, created_at AS date,
+	ts_rank_cd(search_vector, websearch_to_tsquery('english', ?)) AS rank,
+	concat_ws(' ', search_strip_tags(description), search_strip_tags(overview), search_strip_tags(outcomes)) AS body
+FROM projects
+WHERE deleted_at IS NULL AND search_vector @@ websearch_to_tsquery('english', ?)`)
+		args = append(args, query.Text, query.Text)
+	}
+
+	return strings.Join(parts, "\nUNION ALL"), args
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"backend/internal/core/listing"
+	"backend/internal/core/validation"
+	"backend/internal/modules/search/domain"
+	"backend/internal/modules/search/port"
+)
+
+const maxQueryLength = 200
+
+type SearchService struct {
+	repo port.SearchRepository
+}
+
+func NewSearchService(repo port.SearchRepository) *SearchService {
+	return &SearchService{repo: repo}
+}
+
+// Search finds diary entries and projects matching text. types is a comma-separated
+// list of domain types to search, empty for all. Without includePrivate only public,
+// published diary entries are searched.
+func (s *SearchService) Search(ctx context.Context, text, types string, includePrivate bool, params listing.Params) ([]domain.Result, int64, error) {
+	errs := validation.Errors{}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		errs.Add("q", "is required")
+	} else if utf8.RuneCountInString(text) > maxQueryLength {
+		errs.Add("q", "must be at most "+strconv.Itoa(maxQueryLength)+" characters")
+	}
+
+	query := domain.Query{
+		Text:           text,
+		IncludePrivate: includePrivate,
+		Now:            time.Now(),
+		Limit:          params.Limit,
+		Offset:         params.Offset,
+	}
+	for _, t := range strings.Split(types, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if !domain.IsValidType(t) {
+			errs.Add("type", "can only use "+domain.TypeDiary+", "+domain.TypeProject)
+			continue
+		}
+		query.Types = append(query.Types, t)
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.Search(ctx, query)
+}
@@ -2,14 +2,18 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"backend/internal/core/listing"
+	"backend/internal/core/validation"
 	"backend/internal/modules/social/domain"
 	"backend/internal/modules/social/repository"
 	"backend/internal/modules/social/service"
 
 	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
 )
 
 type SocialLinkHandler struct {
@@ -22,12 +26,29 @@ func NewSocialLinkHandler() *SocialLinkHandler {
 	return &SocialLinkHandler{svc: svc}
 }
 
+// Query parameters of GetSocialLinks, see package listing. Links keep their creation
+// order unless sorted.
+var listSpec = listing.Spec{
+	Sorts: []string{"platform"},
+	Filters: []listing.Filter{
+		{Param: "platform", Column: "platform"},
+		{Param: "is_active", Column: "is_active", Kind: listing.FilterBool},
+	},
+}
+
 func (h *SocialLinkHandler) GetSocialLinks(c context.Context, ctx *app.RequestContext) {
-	links, err := h.svc.GetAllSocialLinks(c)
+	params, err := listing.FromRequest(ctx, listSpec)
+	if errs, ok := validation.As(err); ok {
+		ctx.JSON(http.StatusBadRequest, errs.Response())
+		return
+	}
+
+	links, total, err := h.svc.GetAllSocialLinks(c, params)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
+	listing.SetHeaders(ctx, params, total)
 	ctx.JSON(http.StatusOK, links)
 }
 
@@ -73,6 +94,10 @@ func (h *SocialLinkHandler) DeleteSocialLink(c context.Context, ctx *app.Request
 		return
 	}
 	if err := h.svc.DeleteSocialLink(c, uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, map[string]string{"error": "Social link not found"})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
@@ -1,13 +1,15 @@
 package port
 
 import (
+	"backend/internal/core/listing"
 	"backend/internal/modules/social/domain"
 	"context"
 )
 
 type SocialLinkRepository interface {
 	Create(ctx context.Context, link *domain.SocialLinkGorm) error
-	FindAll(ctx context.Context) ([]domain.SocialLinkGorm, error)
+	// FindAll returns a page of links and the total number of matches.
+	FindAll(ctx context.Context, params listing.Params) ([]domain.SocialLinkGorm, int64, error)
 	FindByID(ctx context.Context, id uint) (*domain.SocialLinkGorm, error)
 	Update(ctx context.Context, link *domain.SocialLinkGorm) error
 	Delete(ctx context.Context, id uint) error
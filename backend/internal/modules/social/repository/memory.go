@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"backend/internal/core/listing"
+	"backend/internal/modules/social/domain"
+	"backend/internal/modules/social/port"
+	"context"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemorySocialLinkRepository keeps links in a map, for tests and tools that run without
+// a database.
+type MemorySocialLinkRepository struct {
+	mu     sync.RWMutex
+	links  map[uint]domain.SocialLinkGorm
+	nextID uint
+}
+
+var _ port.SocialLinkRepository = (*MemorySocialLinkRepository)(nil)
+
+func NewMemorySocialLinkRepository() *MemorySocialLinkRepository {
+	return &MemorySocialLinkRepository{links: make(map[uint]domain.SocialLinkGorm)}
+}
+
+func (r *MemorySocialLinkRepository) Create(ctx context.Context, link *domain.SocialLinkGorm) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	now := time.Now()
+	link.ID = r.nextID
+	link.CreatedAt, link.UpdatedAt = now, now
+	// gorm replaces the zero value with the column default, so false can't be inserted
+	link.IsActive = true
+	r.links[link.ID] = *link
+	return nil
+}
+
+func (r *MemorySocialLinkRepository) FindAll(ctx context.Context, params listing.Params) ([]domain.SocialLinkGorm, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	links := make([]domain.SocialLinkGorm, 0, len(r.links))
+	for _, link := range r.links {
+		links = append(links, link)
+	}
+	// Sorted by ID up front, the embedded gorm.Model has no "id" for listing to sort by
+	sort.Slice(links, func(i, j int) bool { return links[i].ID < links[j].ID })
+	page, total := listing.Slice(links, params)
+	return page, total, nil
+}
+
+func (r *MemorySocialLinkRepository) FindByID(ctx context.Context, id uint) (*domain.SocialLinkGorm, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	link, ok := r.links[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &link, nil
+}
+
+func (r *MemorySocialLinkRepository) Update(ctx context.Context, link *domain.SocialLinkGorm) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.links[link.ID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	link.UpdatedAt = time.Now()
+	r.links[link.ID] = *link
+	return nil
+}
+
+func (r *MemorySocialLinkRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.links, id)
+	return nil
+}
+
+// Snapshot implements db.Snapshotter, so a MemoryTransactor can undo writes made in a
+// transaction that fails.
+func (r *MemorySocialLinkRepository) Snapshot() func() {
+	r.mu.RLock()
+	links, nextID := maps.Clone(r.links), r.nextID
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		r.links, r.nextID = links, nextID
+		r.mu.Unlock()
+	}
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"backend/internal/core/db"
+	"backend/internal/core/listing"
 	"backend/internal/modules/social/domain"
 	"backend/internal/modules/social/port"
 	"context"
@@ -16,29 +17,30 @@ func NewPostgresSocialLinkRepository() *PostgresSocialLinkRepository {
 }
 
 func (r *PostgresSocialLinkRepository) Create(ctx context.Context, link *domain.SocialLinkGorm) error {
-	return db.DB.WithContext(ctx).Create(link).Error
+	return db.Conn(ctx).Create(link).Error
 }
 
-func (r *PostgresSocialLinkRepository) FindAll(ctx context.Context) ([]domain.SocialLinkGorm, error) {
+func (r *PostgresSocialLinkRepository) FindAll(ctx context.Context, params listing.Params) ([]domain.SocialLinkGorm, int64, error) {
 	var links []domain.SocialLinkGorm
-	if err := db.DB.WithContext(ctx).Find(&links).Error; err != nil {
-		return nil, err
+	total, err := listing.Find(db.Conn(ctx), params, &links)
+	if err != nil {
+		return nil, 0, err
 	}
-	return links, nil
+	return links, total, nil
 }
 
 func (r *PostgresSocialLinkRepository) FindByID(ctx context.Context, id uint) (*domain.SocialLinkGorm, error) {
 	var link domain.SocialLinkGorm
-	if err := db.DB.WithContext(ctx).First(&link, id).Error; err != nil {
+	if err := db.Conn(ctx).First(&link, id).Error; err != nil {
 		return nil, err
 	}
 	return &link, nil
 }
 
 func (r *PostgresSocialLinkRepository) Update(ctx context.Context, link *domain.SocialLinkGorm) error {
-	return db.DB.WithContext(ctx).Save(link).Error
+	return db.Conn(ctx).Save(link).Error
 }
 
 func (r *PostgresSocialLinkRepository) Delete(ctx context.Context, id uint) error {
-	return db.DB.WithContext(ctx).Delete(&domain.SocialLinkGorm{}, id).Error
+	return db.Conn(ctx).Delete(&domain.SocialLinkGorm{}, id).Error
 }
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"backend/internal/core/audit"
+	"backend/internal/core/listing"
 	"backend/internal/modules/social/domain"
 	"backend/internal/modules/social/port"
 	"context"
@@ -14,12 +16,22 @@ func NewSocialLinkService(repo port.SocialLinkRepository) *SocialLinkService {
 	return &SocialLinkService{repo: repo}
 }
 
-func (s *SocialLinkService) GetAllSocialLinks(ctx context.Context) ([]domain.SocialLinkGorm, error) {
-	return s.repo.FindAll(ctx)
+func (s *SocialLinkService) GetAllSocialLinks(ctx context.Context, params listing.Params) ([]domain.SocialLinkGorm, int64, error) {
+	return s.repo.FindAll(ctx, params)
 }
 
 func (s *SocialLinkService) CreateSocialLink(ctx context.Context, link *domain.SocialLinkGorm) error {
-	return s.repo.Create(ctx, link)
+	if err := s.repo.Create(ctx, link); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{
+		Action:     "social_link.create",
+		TargetType: "social_link",
+		TargetID:   audit.ID(link.ID),
+		Changes:    audit.Diff(nil, link),
+	})
+	return nil
 }
 
 func (s *SocialLinkService) UpdateSocialLink(ctx context.Context, id uint, link *domain.SocialLinkGorm) error {
@@ -28,9 +40,33 @@ func (s *SocialLinkService) UpdateSocialLink(ctx context.Context, id uint, link
 		return err
 	}
 	link.ID = existing.ID
-	return s.repo.Update(ctx, link)
+	if err := s.repo.Update(ctx, link); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{
+		Action:     "social_link.update",
+		TargetType: "social_link",
+		TargetID:   audit.ID(id),
+		Changes:    audit.Diff(existing, link),
+	})
+	return nil
 }
 
 func (s *SocialLinkService) DeleteSocialLink(ctx context.Context, id uint) error {
-	return s.repo.Delete(ctx, id)
+	before, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, audit.Event{
+		Action:     "social_link.delete",
+		TargetType: "social_link",
+		TargetID:   audit.ID(id),
+		Changes:    audit.Diff(before, nil),
+	})
+	return nil
 }
@@ -11,3 +11,24 @@ type SystemConfig struct {
 func (SystemConfig) TableName() string {
 	return "system_configs"
 }
+
+const (
+	ConfigKeySiteName         = "site_name"
+	ConfigKeyRegistrationMode = "registration_mode"
+	ConfigKeyInitialized      = "initialized" // "true" once setup has completed
+)
+
+// Registration modes, public self-registration is closed unless configured otherwise
+const (
+	RegistrationClosed = "closed"
+	RegistrationInvite = "invite"
+	RegistrationOpen   = "open"
+)
+
+func IsValidRegistrationMode(mode string) bool {
+	switch mode {
+	case RegistrationClosed, RegistrationInvite, RegistrationOpen:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"backend/internal/core/validation"
+)
+
+// Site settings keys, stored as SystemConfig rows
+const (
+	ConfigKeyTagline          = "tagline"
+	ConfigKeyAuthorName       = "author_name"
+	ConfigKeyContactEmail     = "contact_email"
+	ConfigKeyDefaultLocale    = "default_locale"
+	ConfigKeyAnalyticsID      = "analytics_id"
+	ConfigKeySEOTitleTemplate = "seo_title_template"
+	ConfigKeySEODescription   = "seo_description"
+	ConfigKeySEOImage         = "seo_image"
+)
+
+// Settings are the site-wide options admins can edit after setup.
+type Settings struct {
+	SiteName         string `json:"site_name"`
+	Tagline          string `json:"tagline"`
+	AuthorName       string `json:"author_name"`
+	ContactEmail     string `json:"contact_email"`
+	DefaultLocale    string `json:"default_locale"`
+	AnalyticsID      string `json:"analytics_id"`
+	SEOTitleTemplate string `json:"seo_title_template"` // "%s" is replaced with the page title
+	SEODescription   string `json:"seo_description"`
+	SEOImage         string `json:"seo_image"` // Default social preview image URL
+}
+
+type settingDefinition struct {
+	key          string
+	defaultValue string
+	field        func(s *Settings) *string
+	validate     func(value string) string // Returns what is wrong, or ""
+}
+
+var (
+	localePattern    = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+	analyticsPattern = regexp.MustCompile(`^[A-Za-z0-9-]{1,32}$`)
+)
+
+var settingDefinitions = []settingDefinition{
+	{
+		key:          ConfigKeySiteName,
+		defaultValue: "Portfolio",
+		field:        func(s *Settings) *string { return &s.SiteName },
+		validate: func(v string) string {
+			if strings.TrimSpace(v) == "" {
+				return "is required"
+			}
+			return maxLength(v, 100)
+		},
+	},
+	{
+		key:      ConfigKeyTagline,
+		field:    func(s *Settings) *string { return &s.Tagline },
+		validate: func(v string) string { return maxLength(v, 200) },
+	},
+	{
+		key:      ConfigKeyAuthorName,
+		field:    func(s *Settings) *string { return &s.AuthorName },
+		validate: func(v string) string { return maxLength(v, 100) },
+	},
+	{
+		key:   ConfigKeyContactEmail,
+		field: func(s *Settings) *string { return &s.ContactEmail },
+		validate: func(v string) string {
+			if v == "" {
+				return ""
+			}
+			return validation.Email(v)
+		},
+	},
+	{
+		key:          ConfigKeyDefaultLocale,
+		defaultValue: "en",
+		field:        func(s *Settings) *string { return &s.DefaultLocale },
+		validate: func(v string) string {
+			if !localePattern.MatchString(v) {
+				return "must be a language tag like \"en\" or \"zh-CN\""
+			}
+			return ""
+		},
+	},
+	{
+		key:   ConfigKeyAnalyticsID,
+		field: func(s *Settings) *string { return &s.AnalyticsID },
+		validate: func(v string) string {
+			if v != "" && !analyticsPattern.MatchString(v) {
+				return "must be a tracking ID like \"G-XXXXXXX\""
+			}
+			return ""
+		},
+	},
+	{
+		key:          ConfigKeySEOTitleTemplate,
+		defaultValue: "%s",
+		field:        func(s *Settings) *string { return &s.SEOTitleTemplate },
+		validate: func(v string) string {
+			if strings.Count(v, "%s") != 1 {
+				return "must contain \"%s\" exactly once"
+			}
+			return maxLength(v, 100)
+		},
+	},
+	{
+		key:      ConfigKeySEODescription,
+		field:    func(s *Settings) *string { return &s.SEODescription },
+		validate: func(v string) string { return maxLength(v, 300) },
+	},
+	{
+		key:   ConfigKeySEOImage,
+		field: func(s *Settings) *string { return &s.SEOImage },
+		validate: func(v string) string {
+			if v == "" {
+				return ""
+			}
+			u, err := url.Parse(v)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return "must be an http or https URL"
+			}
+			return maxLength(v, 500)
+		},
+	},
+}
+
+func maxLength(v string, n int) string {
+	if len([]rune(v)) > n {
+		return fmt.Sprintf("must be at most %d characters", n)
+	}
+	return ""
+}
+
+// SettingsFromConfig builds Settings from stored values, using defaults for missing keys.
+func SettingsFromConfig(values map[string]string) *Settings {
+	settings := &Settings{}
+	for _, def := range settingDefinitions {
+		value, ok := values[def.key]
+		if !ok || value == "" {
+			value = def.defaultValue
+		}
+		*def.field(settings) = value
+	}
+	return settings
+}
+
+// ValidateSettings checks a partial update keyed by setting name.
+func ValidateSettings(update map[string]string) error {
+	errs := validation.Errors{}
+	for key, value := range update {
+		def, ok := findSetting(key)
+		if !ok {
+			errs.Add(key, "is not a known setting")
+			continue
+		}
+		errs.Add(key, def.validate(value))
+	}
+	return errs.Err()
+}
+
+func findSetting(key string) (settingDefinition, bool) {
+	for _, def := range settingDefinitions {
+		if def.key == key {
+			return def, true
+		}
+	}
+	return settingDefinition{}, false
+}
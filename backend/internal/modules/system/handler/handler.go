@@ -2,8 +2,12 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
+	"backend/internal/core/db"
+	"backend/internal/core/mailer"
+	"backend/internal/core/validation"
 	"backend/internal/modules/auth/service"
 	"backend/internal/modules/system/repository"
 	systemService "backend/internal/modules/system/service"
@@ -18,14 +22,24 @@ type SystemHandler struct {
 
 func NewSystemHandler() *SystemHandler {
 	// Wiring
-	sysRepo := repository.NewPostgresSystemRepository()
+	sysRepo := repository.NewCachedSystemRepository()
 	usrRepo := userRepo.NewPostgresUserRepository()
-	authSvc := service.NewAuthService(usrRepo)
-	svc := systemService.NewSystemService(sysRepo, usrRepo, authSvc)
+	refreshRepo := userRepo.NewPostgresRefreshTokenRepository()
+	inviteRepo := userRepo.NewPostgresInviteRepository()
+	attemptRepo := userRepo.NewPostgresLoginAttemptRepository()
+	apiTokenRepo := userRepo.NewPostgresAPITokenRepository()
+	authSvc := service.NewAuthService(usrRepo, refreshRepo, inviteRepo, attemptRepo, apiTokenRepo, sysRepo, mailer.Default)
+	svc := systemService.NewSystemService(sysRepo, usrRepo, authSvc, db.NewPostgresTransactor())
 
 	return &SystemHandler{svc: svc}
 }
 
+// IssueSetupToken is called at startup when SETUP_REQUIRE_TOKEN is set, see
+// SystemService.IssueSetupToken.
+func (h *SystemHandler) IssueSetupToken(c context.Context) (string, error) {
+	return h.svc.IssueSetupToken(c)
+}
+
 func (h *SystemHandler) GetStatus(c context.Context, ctx *app.RequestContext) {
 	status, err := h.svc.GetStatus(c)
 	if err != nil {
@@ -36,10 +50,11 @@ func (h *SystemHandler) GetStatus(c context.Context, ctx *app.RequestContext) {
 }
 
 type SetupRequest struct {
-	SiteName string `json:"site_name"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	SetupToken string `json:"setup_token"` // Only when the server was started with SETUP_REQUIRE_TOKEN
+	SiteName   string `json:"site_name"`
+	Username   string `json:"username"`
+	Email      string `json:"email"`
+	Password   string `json:"password"`
 }
 
 func (h *SystemHandler) Setup(c context.Context, ctx *app.RequestContext) {
@@ -49,10 +64,70 @@ func (h *SystemHandler) Setup(c context.Context, ctx *app.RequestContext) {
 		return
 	}
 
-	if err := h.svc.Setup(c, req.SiteName, req.Username, req.Email, req.Password); err != nil {
+	if err := h.svc.Setup(c, req.SetupToken, req.SiteName, req.Username, req.Email, req.Password); err != nil {
+		if errs, ok := validation.As(err); ok {
+			ctx.JSON(http.StatusBadRequest, errs.Response())
+			return
+		}
+		if errors.Is(err, systemService.ErrInvalidSetupToken) {
+			ctx.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, systemService.ErrAlreadyInitialized) {
+			ctx.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, map[string]string{"message": "System setup complete"})
 }
+
+func (h *SystemHandler) GetSettings(c context.Context, ctx *app.RequestContext) {
+	settings, err := h.svc.GetSettings(c)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings takes any subset of the settings fields, e.g. {"tagline": "..."}.
+func (h *SystemHandler) UpdateSettings(c context.Context, ctx *app.RequestContext) {
+	var update map[string]string
+	if err := ctx.BindJSON(&update); err != nil {
+		ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Expected a JSON object of string settings"})
+		return
+	}
+
+	settings, err := h.svc.UpdateSettings(c, update)
+	if err != nil {
+		if errs, ok := validation.As(err); ok {
+			ctx.JSON(http.StatusBadRequest, errs.Response())
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, settings)
+}
+
+type RegistrationModeRequest struct {
+	Mode string `json:"mode"`
+}
+
+func (h *SystemHandler) SetRegistrationMode(c context.Context, ctx *app.RequestContext) {
+	var req RegistrationModeRequest
+	if err := ctx.BindAndValidate(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := h.svc.SetRegistrationMode(c, req.Mode); err != nil {
+		ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]string{"mode": req.Mode})
+}
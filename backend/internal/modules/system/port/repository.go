@@ -0,0 +1,16 @@
+package port
+
+import (
+	"context"
+)
+
+type SystemRepository interface {
+	// GetConfig returns gorm.ErrRecordNotFound when the key has never been set.
+	GetConfig(ctx context.Context, key string) (string, error)
+	SetConfig(ctx context.Context, key, value string) error
+	// GetAllConfig returns every stored key and its value. The map must not be modified.
+	GetAllConfig(ctx context.Context) (map[string]string, error)
+	// Invalidate drops cached values, it is called after a transaction that changed
+	// config has ended. Implementations without a cache do nothing.
+	Invalidate()
+}
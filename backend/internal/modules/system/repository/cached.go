@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"backend/internal/core/config"
+	"backend/internal/core/db"
+	"backend/internal/modules/system/port"
+
+	"gorm.io/gorm"
+)
+
+// CachedSystemRepository keeps all system config in memory in front of
+// PostgresSystemRepository. SetConfig invalidates the cache; the TTL bounds how long
+// other instances of the API can serve a value changed elsewhere.
+type CachedSystemRepository struct {
+	repo *PostgresSystemRepository
+
+	mu         sync.RWMutex
+	values     map[string]string // Nil when not loaded
+	loadedAt   time.Time
+	generation uint64 // Bumped by Invalidate, so a load that raced with it isn't stored
+}
+
+var _ port.SystemRepository = (*CachedSystemRepository)(nil)
+
+var (
+	sharedCacheOnce sync.Once
+	sharedCache     *CachedSystemRepository
+)
+
+// NewCachedSystemRepository returns the process-wide cache, so that every service sees
+// invalidations made through any of them.
+func NewCachedSystemRepository() *CachedSystemRepository {
+	sharedCacheOnce.Do(func() {
+		sharedCache = &CachedSystemRepository{repo: NewPostgresSystemRepository()}
+	})
+	return sharedCache
+}
+
+func (r *CachedSystemRepository) GetConfig(ctx context.Context, key string) (string, error) {
+	values, err := r.GetAllConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	value, ok := values[key]
+	if !ok {
+		return "", gorm.ErrRecordNotFound
+	}
+	return value, nil
+}
+
+// GetAllConfig returns a copy of every stored key and its value.
+func (r *CachedSystemRepository) GetAllConfig(ctx context.Context) (map[string]string, error) {
+	// Inside a transaction the cache could hold or pick up uncommitted values
+	if db.InTransaction(ctx) {
+		return r.repo.GetAllConfig(ctx)
+	}
+
+	r.mu.RLock()
+	values, fresh := r.values, time.Since(r.loadedAt) < config.SettingsCacheTTL
+	generation := r.generation
+	r.mu.RUnlock()
+	if values != nil && fresh {
+		return maps.Clone(values), nil
+	}
+
+	values, err := r.repo.GetAllConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	// An Invalidate during the load means values may predate a write, serve it only to
+	// this caller and let the next one reload
+	if r.generation == generation {
+		r.values = values
+		r.loadedAt = time.Now()
+	}
+	r.mu.Unlock()
+	return maps.Clone(values), nil
+}
+
+func (r *CachedSystemRepository) SetConfig(ctx context.Context, key, value string) error {
+	err := r.repo.SetConfig(ctx, key, value)
+	r.Invalidate()
+	return err
+}
+
+func (r *CachedSystemRepository) Invalidate() {
+	r.mu.Lock()
+	r.values = nil
+	r.generation++
+	r.mu.Unlock()
+}
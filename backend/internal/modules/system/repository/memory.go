@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"backend/internal/modules/system/port"
+
+	"gorm.io/gorm"
+)
+
+// MemorySystemRepository keeps system config in a map, for tests and tools that run
+// without a database.
+type MemorySystemRepository struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+var _ port.SystemRepository = (*MemorySystemRepository)(nil)
+
+func NewMemorySystemRepository() *MemorySystemRepository {
+	return &MemorySystemRepository{values: make(map[string]string)}
+}
+
+func (r *MemorySystemRepository) GetConfig(ctx context.Context, key string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	value, ok := r.values[key]
+	if !ok {
+		return "", gorm.ErrRecordNotFound
+	}
+	return value, nil
+}
+
+func (r *MemorySystemRepository) SetConfig(ctx context.Context, key, value string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[key] = value
+	return nil
+}
+
+func (r *MemorySystemRepository) GetAllConfig(ctx context.Context) (map[string]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return maps.Clone(r.values), nil
+}
+
+func (r *MemorySystemRepository) Invalidate() {}
+
+// Snapshot implements db.Snapshotter, so a MemoryTransactor can undo writes made in a
+// transaction that fails.
+func (r *MemorySystemRepository) Snapshot() func() {
+	r.mu.RLock()
+	values := maps.Clone(r.values)
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		r.values = values
+		r.mu.Unlock()
+	}
+}
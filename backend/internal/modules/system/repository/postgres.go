@@ -3,18 +3,21 @@ package repository
 import (
 	"backend/internal/core/db"
 	"backend/internal/modules/system/domain"
+	"backend/internal/modules/system/port"
 	"context"
 )
 
 type PostgresSystemRepository struct{}
 
+var _ port.SystemRepository = (*PostgresSystemRepository)(nil)
+
 func NewPostgresSystemRepository() *PostgresSystemRepository {
 	return &PostgresSystemRepository{}
 }
 
 func (r *PostgresSystemRepository) GetConfig(ctx context.Context, key string) (string, error) {
 	var config domain.SystemConfig
-	if err := db.DB.WithContext(ctx).Where("key = ?", key).First(&config).Error; err != nil {
+	if err := db.Conn(ctx).Where("key = ?", key).First(&config).Error; err != nil {
 		return "", err
 	}
 	return config.Value, nil
@@ -23,13 +26,30 @@ func (r *PostgresSystemRepository) GetConfig(ctx context.Context, key string) (s
 func (r *PostgresSystemRepository) SetConfig(ctx context.Context, key, value string) error {
 	var config domain.SystemConfig
 	// Check if exists
-	err := db.DB.WithContext(ctx).Where("key = ?", key).First(&config).Error
+	err := db.Conn(ctx).Where("key = ?", key).First(&config).Error
 	if err == nil {
 		// Update
 		config.Value = value
-		return db.DB.WithContext(ctx).Save(&config).Error
+		return db.Conn(ctx).Save(&config).Error
 	}
 	// Create
 	newConfig := domain.SystemConfig{Key: key, Value: value}
-	return db.DB.WithContext(ctx).Create(&newConfig).Error
+	return db.Conn(ctx).Create(&newConfig).Error
 }
+
+// GetAllConfig returns every stored key and its value.
+func (r *PostgresSystemRepository) GetAllConfig(ctx context.Context) (map[string]string, error) {
+	var configs []domain.SystemConfig
+	if err := db.Conn(ctx).Find(&configs).Error; err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(configs))
+	for _, config := range configs {
+		values[config.Key] = config.Value
+	}
+	return values, nil
+}
+
+// Invalidate does nothing, every call reads from the database.
+func (r *PostgresSystemRepository) Invalidate() {}
@@ -2,72 +2,284 @@ package service
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
 
+	"backend/internal/core/audit"
+	"backend/internal/core/db"
+	"backend/internal/core/rbac"
+	"backend/internal/core/utils"
+	"backend/internal/core/validation"
 	"backend/internal/modules/auth/service"
-	"backend/internal/modules/system/repository"
+	"backend/internal/modules/system/domain"
+	"backend/internal/modules/system/port"
+	userDomain "backend/internal/modules/user/domain"
 	userRepo "backend/internal/modules/user/port"
 )
 
+// Arbitrary key of the advisory lock serializing Setup
+const setupLockKey int64 = 0x706f72746673
+
+var (
+	ErrAlreadyInitialized = errors.New("system already initialized")
+	ErrInvalidSetupToken  = errors.New("invalid setup token, see the server log")
+)
+
 type SystemService struct {
-	systemRepo *repository.PostgresSystemRepository
+	systemRepo port.SystemRepository
 	userRepo   userRepo.UserRepository
 	authSvc    *service.AuthService
+	tx         db.Transactor
+
+	initialized atomic.Bool // Never goes back to false, so it is safe to cache forever
+
+	setupMu        sync.Mutex
+	setupTokenHash string // Empty when no setup token is required
 }
 
 func NewSystemService(
-	systemRepo *repository.PostgresSystemRepository,
+	systemRepo port.SystemRepository,
 	userRepo userRepo.UserRepository,
 	authSvc *service.AuthService,
+	tx db.Transactor,
 ) *SystemService {
 	return &SystemService{
 		systemRepo: systemRepo,
 		userRepo:   userRepo,
 		authSvc:    authSvc,
+		tx:         tx,
 	}
 }
 
 type SystemStatus struct {
-	Initialized bool   `json:"initialized"`
-	SiteName    string `json:"site_name"`
+	Initialized        bool   `json:"initialized"`
+	SetupTokenRequired bool   `json:"setup_token_required"`
+	SiteName           string `json:"site_name"`
+	RegistrationMode   string `json:"registration_mode"`
 }
 
 func (s *SystemService) GetStatus(ctx context.Context) (*SystemStatus, error) {
-	count, err := s.userRepo.Count(ctx)
+	initialized, err := s.isInitialized(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	siteName, _ := s.systemRepo.GetConfig(ctx, "site_name")
+	siteName, _ := s.systemRepo.GetConfig(ctx, domain.ConfigKeySiteName)
 	if siteName == "" {
 		siteName = "Portfolio"
 	}
 
 	return &SystemStatus{
-		Initialized: count > 0,
-		SiteName:    siteName,
+		Initialized:        initialized,
+		SetupTokenRequired: !initialized && s.setupTokenRequired(),
+		SiteName:           siteName,
+		RegistrationMode:   s.authSvc.RegistrationMode(ctx),
 	}, nil
 }
 
-func (s *SystemService) Setup(ctx context.Context, siteName, username, email, password string) error {
-	// 1. Ensure not initialized
+// isInitialized reports whether setup has completed. After the first true answer it no
+// longer touches the database.
+func (s *SystemService) isInitialized(ctx context.Context) (bool, error) {
+	if s.initialized.Load() {
+		return true, nil
+	}
+	if value, _ := s.systemRepo.GetConfig(ctx, domain.ConfigKeyInitialized); value == "true" {
+		s.initialized.Store(true)
+		return true, nil
+	}
+
+	// Installs set up before the flag existed only have their users to go by
 	count, err := s.userRepo.Count(ctx)
+	if err != nil || count == 0 {
+		return false, err
+	}
+	if err := s.systemRepo.SetConfig(ctx, domain.ConfigKeyInitialized, "true"); err != nil {
+		return false, err
+	}
+	s.initialized.Store(true)
+	return true, nil
+}
+
+// Setup creates the owner account and the initial settings. It runs in one transaction
+// under a lock, so concurrent requests can't both create an owner and a failure part
+// way leaves the system uninitialized.
+func (s *SystemService) Setup(ctx context.Context, setupToken, siteName, username, email, password string) error {
+	if s.initialized.Load() {
+		return ErrAlreadyInitialized
+	}
+	if !s.checkSetupToken(setupToken) {
+		return ErrInvalidSetupToken
+	}
+
+	errs := validation.Account(username, email, password)
+	if strings.TrimSpace(siteName) == "" {
+		errs.Add("site_name", "is required")
+	}
+	if err := errs.Err(); err != nil {
+		return err
+	}
+
+	var owner *userDomain.User
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.tx.Lock(ctx, setupLockKey); err != nil {
+			return err
+		}
+
+		// 1. Ensure not initialized, checked under the lock so only one request gets past
+		count, err := s.userRepo.Count(ctx)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAlreadyInitialized
+		}
+
+		// 2. Create Admin User (bypasses the registration mode)
+		if owner, err = s.authSvc.CreateUser(ctx, username, email, password, rbac.RoleOwner); err != nil {
+			return err
+		}
+
+		// 3. Set Site Name
+		if err := s.systemRepo.SetConfig(ctx, domain.ConfigKeySiteName, siteName); err != nil {
+			return err
+		}
+
+		// 4. Close public registration until an admin opens it
+		if err := s.systemRepo.SetConfig(ctx, domain.ConfigKeyRegistrationMode, domain.RegistrationClosed); err != nil {
+			return err
+		}
+
+		return s.systemRepo.SetConfig(ctx, domain.ConfigKeyInitialized, "true")
+	})
+	// Drop anything cached while the transaction was still open
+	s.systemRepo.Invalidate()
 	if err != nil {
 		return err
 	}
-	if count > 0 {
-		return errors.New("system already initialized")
+
+	// Recorded after commit, so a rolled back setup leaves no audit trail behind
+	audit.Record(ctx, audit.Event{
+		Action:     "user.create",
+		TargetType: "user",
+		TargetID:   audit.ID(owner.ID),
+		Changes:    audit.Diff(nil, owner),
+	})
+	audit.Record(ctx, audit.Event{
+		Action:     "system.setup",
+		TargetType: "system",
+		Changes: audit.Diff(nil, map[string]string{
+			domain.ConfigKeySiteName:         siteName,
+			domain.ConfigKeyRegistrationMode: domain.RegistrationClosed,
+		}),
+	})
+
+	s.initialized.Store(true)
+	s.clearSetupToken()
+	return nil
+}
+
+// IssueSetupToken generates the one-time token Setup then requires, for installs that
+// are reachable by others before the owner has set them up. It returns "" once the
+// system is initialized. The token only lives in memory, so it changes on restart.
+func (s *SystemService) IssueSetupToken(ctx context.Context) (string, error) {
+	initialized, err := s.isInitialized(ctx)
+	if err != nil || initialized {
+		return "", err
+	}
+
+	token, err := utils.RandomString(24)
+	if err != nil {
+		return "", err
 	}
 
-	// 2. Create Admin User
-	if err := s.authSvc.Register(ctx, username, email, password); err != nil {
-		return err
+	s.setupMu.Lock()
+	s.setupTokenHash = utils.HashToken(token)
+	s.setupMu.Unlock()
+	return token, nil
+}
+
+func (s *SystemService) setupTokenRequired() bool {
+	s.setupMu.Lock()
+	defer s.setupMu.Unlock()
+	return s.setupTokenHash != ""
+}
+
+func (s *SystemService) checkSetupToken(token string) bool {
+	s.setupMu.Lock()
+	defer s.setupMu.Unlock()
+	if s.setupTokenHash == "" {
+		return true
 	}
+	return subtle.ConstantTimeCompare([]byte(utils.HashToken(token)), []byte(s.setupTokenHash)) == 1
+}
+
+func (s *SystemService) clearSetupToken() {
+	s.setupMu.Lock()
+	s.setupTokenHash = ""
+	s.setupMu.Unlock()
+}
 
-	// 3. Set Site Name
-	if err := s.systemRepo.SetConfig(ctx, "site_name", siteName); err != nil {
+func (s *SystemService) SetRegistrationMode(ctx context.Context, mode string) error {
+	if !domain.IsValidRegistrationMode(mode) {
+		return errors.New("invalid registration mode, expected one of: closed, invite, open")
+	}
+
+	previous := s.authSvc.RegistrationMode(ctx)
+	if err := s.systemRepo.SetConfig(ctx, domain.ConfigKeyRegistrationMode, mode); err != nil {
 		return err
 	}
 
+	audit.Record(ctx, audit.Event{
+		Action:     "system.registration_mode",
+		TargetType: "system",
+		Changes: audit.Diff(
+			map[string]string{domain.ConfigKeyRegistrationMode: previous},
+			map[string]string{domain.ConfigKeyRegistrationMode: mode},
+		),
+	})
 	return nil
 }
+
+func (s *SystemService) GetSettings(ctx context.Context) (*domain.Settings, error) {
+	values, err := s.systemRepo.GetAllConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return domain.SettingsFromConfig(values), nil
+}
+
+// UpdateSettings applies a partial update keyed by setting name, all or nothing.
+func (s *SystemService) UpdateSettings(ctx context.Context, update map[string]string) (*domain.Settings, error) {
+	if err := domain.ValidateSettings(update); err != nil {
+		return nil, err
+	}
+
+	var before, after *domain.Settings
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if before, err = s.GetSettings(ctx); err != nil {
+			return err
+		}
+		for key, value := range update {
+			if err := s.systemRepo.SetConfig(ctx, key, value); err != nil {
+				return err
+			}
+		}
+		after, err = s.GetSettings(ctx)
+		return err
+	})
+	s.systemRepo.Invalidate()
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Record(ctx, audit.Event{
+		Action:     "system.settings_update",
+		TargetType: "system",
+		Changes:    audit.Diff(before, after),
+	})
+	return after, nil
+}
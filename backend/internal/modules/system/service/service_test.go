@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"backend/internal/core/db"
+	"backend/internal/core/rbac"
+	"backend/internal/modules/auth/service/authtest"
+	"backend/internal/modules/system/domain"
+	"backend/internal/modules/system/repository"
+	userRepository "backend/internal/modules/user/repository"
+)
+
+// failingSystemRepository fails to store one key, to abort Setup part way.
+type failingSystemRepository struct {
+	*repository.MemorySystemRepository
+	failKey string
+}
+
+func (r *failingSystemRepository) SetConfig(ctx context.Context, key, value string) error {
+	if key == r.failKey {
+		return errors.New("storage failure")
+	}
+	return r.MemorySystemRepository.SetConfig(ctx, key, value)
+}
+
+type systemFixture struct {
+	svc   *SystemService
+	users *userRepository.MemoryUserRepository
+}
+
+func newSystemFixture(t *testing.T, failKey string) *systemFixture {
+	t.Helper()
+
+	systemRepo := &failingSystemRepository{MemorySystemRepository: repository.NewMemorySystemRepository(), failKey: failKey}
+	auth := authtest.New(t, systemRepo)
+	tx := db.NewMemoryTransactor(systemRepo, auth.Users)
+	return &systemFixture{svc: NewSystemService(systemRepo, auth.Users, auth.Auth, tx), users: auth.Users}
+}
+
+func TestSetupCreatesOwnerOnce(t *testing.T) {
+	f := newSystemFixture(t, "")
+	ctx := context.Background()
+
+	if err := f.svc.Setup(ctx, "", "My site", "owner", "owner@example.com", authtest.Password); err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+
+	owner, err := f.users.FindByEmail(ctx, "owner@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner.Role != rbac.RoleOwner {
+		t.Fatalf("expected the owner role, got %q", owner.Role)
+	}
+
+	status, err := f.svc.GetStatus(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Initialized || status.SiteName != "My site" || status.RegistrationMode != domain.RegistrationClosed {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	err = f.svc.Setup(ctx, "", "Other site", "intruder", "intruder@example.com", authtest.Password)
+	if !errors.Is(err, ErrAlreadyInitialized) {
+		t.Fatalf("expected ErrAlreadyInitialized, got %v", err)
+	}
+}
+
+func TestSetupFailureLeavesSystemUninitialized(t *testing.T) {
+	f := newSystemFixture(t, domain.ConfigKeyInitialized)
+	ctx := context.Background()
+
+	if err := f.svc.Setup(ctx, "", "My site", "owner", "owner@example.com", authtest.Password); err == nil {
+		t.Fatal("expected Setup to fail")
+	}
+
+	if count, _ := f.users.Count(ctx); count != 0 {
+		t.Fatalf("expected the owner to be rolled back, found %d users", count)
+	}
+	status, err := f.svc.GetStatus(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Initialized || status.SiteName != "Portfolio" {
+		t.Fatalf("expected an uninitialized system, got %+v", status)
+	}
+}
+
+func TestSetupRejectsInvalidInput(t *testing.T) {
+	f := newSystemFixture(t, "")
+
+	err := f.svc.Setup(context.Background(), "", " ", "owner", "not-an-email", authtest.Password)
+	if err == nil {
+		t.Fatal("expected a validation error")
+	}
+	if count, _ := f.users.Count(context.Background()); count != 0 {
+		t.Fatalf("expected no user, found %d", count)
+	}
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// APITokenPrefix marks personal access tokens so they can be told apart from JWTs.
+const APITokenPrefix = "pcms_"
+
+// APIToken is a long-lived personal access token for scripts and CI. Only the hash is
+// stored, the raw token is shown once on creation.
+type APIToken struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UserID     uint           `gorm:"index;not null" json:"user_id"`
+	Name       string         `gorm:"not null;size:100" json:"name"`
+	Prefix     string         `gorm:"size:16" json:"prefix"` // First characters of the token, to recognise it in listings
+	TokenHash  string         `gorm:"uniqueIndex;not null;size:64" json:"-"`
+	Scopes     pq.StringArray `gorm:"type:text[]" json:"scopes"`
+	ExpiresAt  *time.Time     `json:"expires_at"` // Nil never expires
+	LastUsedAt *time.Time     `json:"last_used_at"`
+	RevokedAt  *time.Time     `json:"revoked_at"`
+}
+
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
+
+func (t *APIToken) IsUsable(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
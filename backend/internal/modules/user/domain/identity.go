@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// Identity links an account at an external identity provider to a user.
+type Identity struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UserID    uint      `gorm:"index;not null" json:"user_id"`
+	Provider  string    `gorm:"uniqueIndex:idx_identity_provider_subject;not null;size:32" json:"provider"`
+	Subject   string    `gorm:"uniqueIndex:idx_identity_provider_subject;not null;size:255" json:"subject"`
+	Email     string    `gorm:"size:255" json:"email"` // Email at the provider when the link was made
+}
+
+func (Identity) TableName() string {
+	return "user_identities"
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// Invite is an admin-issued, single-use token that allows registering while
+// registration is invite-only.
+type Invite struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Email       string     `gorm:"size:255" json:"email"` // Optional, restricts the invite to this address
+	Role        string     `gorm:"not null;size:32;default:'viewer'" json:"role"`
+	TokenHash   string     `gorm:"uniqueIndex;not null;size:64" json:"-"`
+	CreatedByID uint       `gorm:"index" json:"created_by_id"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt      *time.Time `json:"used_at"`
+	UsedByID    *uint      `json:"used_by_id"`
+}
+
+func (Invite) TableName() string {
+	return "invites"
+}
+
+func (i *Invite) IsUsable(now time.Time) bool {
+	return i.UsedAt == nil && now.Before(i.ExpiresAt)
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// LoginAttempt records a failed login for auditing.
+type LoginAttempt struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	Email     string    `gorm:"index;size:255" json:"email"`
+	UserID    *uint     `gorm:"index" json:"user_id"` // Nil when the email doesn't match an account
+	IP        string    `gorm:"index;size:64" json:"ip"`
+	UserAgent string    `gorm:"size:512" json:"user_agent"`
+	Reason    string    `gorm:"size:64" json:"reason"`
+}
+
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
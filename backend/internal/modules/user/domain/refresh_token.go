@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// RefreshToken is a persisted, single-use refresh token. Tokens issued from the same
+// login share a FamilyID so the whole chain can be revoked when reuse is detected.
+type RefreshToken struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UserID       uint       `gorm:"index;not null" json:"user_id"`
+	FamilyID     string     `gorm:"index;not null;size:64" json:"family_id"`
+	TokenHash    string     `gorm:"uniqueIndex;not null;size:64" json:"-"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	ReplacedByID *uint      `json:"replaced_by_id"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return now.After(t.ExpiresAt)
+}
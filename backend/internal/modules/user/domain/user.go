@@ -3,6 +3,7 @@ package domain
 import (
 	"time"
 
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
@@ -11,9 +12,18 @@ type User struct {
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
-	Username  string         `gorm:"uniqueIndex;not null;size:255" json:"username"`
-	Email     string         `gorm:"uniqueIndex;not null;size:255" json:"email"`
+	Username  string         `gorm:"uniqueIndex:idx_users_username_live,where:deleted_at IS NULL;not null;size:255" json:"username"` // Deleted users free their username and email
+	Email     string         `gorm:"uniqueIndex:idx_users_email_live,where:deleted_at IS NULL;not null;size:255" json:"email"`
 	Password  string         `gorm:"not null" json:"-"` // Password should not be exposed in JSON
+	Role      string         `gorm:"not null;size:32;default:'viewer'" json:"role"`
+	IsActive  bool           `gorm:"not null;default:true" json:"is_active"` // Deactivated users can't log in
+
+	// Two-factor authentication. The secret is stored once enrollment starts but only
+	// enforced after TOTPEnabled is set by the confirmation step.
+	TOTPSecret    string         `gorm:"size:64" json:"-"`
+	TOTPEnabled   bool           `gorm:"default:false" json:"totp_enabled"`
+	TOTPLastStep  int64          `json:"-"`                    // Last accepted time step, prevents code replay
+	RecoveryCodes pq.StringArray `gorm:"type:text[]" json:"-"` // SHA-256 hashes of unused recovery codes
 }
 
 func (User) TableName() string {
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/core/db"
+	"backend/internal/core/listing"
+	"backend/internal/core/mailer"
+	"backend/internal/core/server/middleware"
+	"backend/internal/core/validation"
+	authService "backend/internal/modules/auth/service"
+	sysRepo "backend/internal/modules/system/repository"
+	"backend/internal/modules/user/repository"
+	"backend/internal/modules/user/service"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"gorm.io/gorm"
+)
+
+type UserHandler struct {
+	svc *service.UserService
+}
+
+func NewUserHandler() *UserHandler {
+	repo := repository.NewPostgresUserRepository()
+	refreshRepo := repository.NewPostgresRefreshTokenRepository()
+	inviteRepo := repository.NewPostgresInviteRepository()
+	attemptRepo := repository.NewPostgresLoginAttemptRepository()
+	apiTokenRepo := repository.NewPostgresAPITokenRepository()
+	systemRepo := sysRepo.NewCachedSystemRepository()
+	authSvc := authService.NewAuthService(repo, refreshRepo, inviteRepo, attemptRepo, apiTokenRepo, systemRepo, mailer.Default)
+	svc := service.NewUserService(repo, refreshRepo, authSvc, db.NewPostgresTransactor())
+	return &UserHandler{svc: svc}
+}
+
+var listSpec = listing.Spec{
+	Sorts: []string{"created_at", "username", "email", "role"},
+	Filters: []listing.Filter{
+		{Param: "role", Column: "role"},
+		{Param: "is_active", Column: "is_active", Kind: listing.FilterBool},
+	},
+	DateColumn:   "created_at",
+	DefaultLimit: 50,
+}
+
+type ProfileRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+func (h *UserHandler) GetUsers(c context.Context, ctx *app.RequestContext) {
+	params, err := listing.FromRequest(ctx, listSpec)
+	if errs, ok := validation.As(err); ok {
+		ctx.JSON(http.StatusBadRequest, errs.Response())
+		return
+	}
+
+	users, total, err := h.svc.GetAllUsers(c, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	listing.SetHeaders(ctx, params, total)
+	ctx.JSON(http.StatusOK, users)
+}
+
+func (h *UserHandler) GetUser(c context.Context, ctx *app.RequestContext) {
+	id, ok := parseID(ctx)
+	if !ok {
+		return
+	}
+	user, err := h.svc.GetUser(c, id)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, user)
+}
+
+func (h *UserHandler) CreateUser(c context.Context, ctx *app.RequestContext) {
+	var input service.UserInput
+	if err := ctx.BindAndValidate(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	user, err := h.svc.CreateUser(c, input)
+	if err != nil {
+		if errs, ok := validation.As(err); ok {
+			ctx.JSON(http.StatusBadRequest, errs.Response())
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, user)
+}
+
+func (h *UserHandler) UpdateUser(c context.Context, ctx *app.RequestContext) {
+	id, ok := parseID(ctx)
+	if !ok {
+		return
+	}
+
+	var input service.UserInput
+	if err := ctx.BindAndValidate(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	user, err := h.svc.UpdateUser(c, id, input)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, user)
+}
+
+func (h *UserHandler) DeactivateUser(c context.Context, ctx *app.RequestContext) {
+	h.setActive(c, ctx, false)
+}
+
+func (h *UserHandler) ActivateUser(c context.Context, ctx *app.RequestContext) {
+	h.setActive(c, ctx, true)
+}
+
+func (h *UserHandler) setActive(c context.Context, ctx *app.RequestContext, active bool) {
+	id, ok := parseID(ctx)
+	if !ok {
+		return
+	}
+	user, err := h.svc.SetActive(c, id, active)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, user)
+}
+
+func (h *UserHandler) DeleteUser(c context.Context, ctx *app.RequestContext) {
+	id, ok := parseID(ctx)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteUser(c, id); err != nil {
+		writeError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, map[string]string{"message": "User deleted"})
+}
+
+func (h *UserHandler) GetMe(c context.Context, ctx *app.RequestContext) {
+	user, err := h.svc.GetUser(c, ctx.GetUint(middleware.ContextUserID))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, user)
+}
+
+func (h *UserHandler) UpdateMe(c context.Context, ctx *app.RequestContext) {
+	var req ProfileRequest
+	if err := ctx.BindAndValidate(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	user, err := h.svc.UpdateProfile(c, ctx.GetUint(middleware.ContextUserID), req.Username, req.Email)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, user)
+}
+
+func parseID(ctx *app.RequestContext) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func writeError(ctx *app.RequestContext, err error) {
+	if errs, ok := validation.As(err); ok {
+		ctx.JSON(http.StatusBadRequest, errs.Response())
+		return
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	case errors.Is(err, service.ErrLastOwner), errors.Is(err, service.ErrUserExists):
+		ctx.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidRole):
+		ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,15 @@
+package port
+
+import (
+	"backend/internal/modules/user/domain"
+	"context"
+	"time"
+)
+
+type APITokenRepository interface {
+	Create(ctx context.Context, token *domain.APIToken) error
+	FindByUser(ctx context.Context, userID uint) ([]domain.APIToken, error)
+	FindByHash(ctx context.Context, hash string) (*domain.APIToken, error)
+	TouchLastUsed(ctx context.Context, id uint, at time.Time) error
+	Revoke(ctx context.Context, userID, id uint) error
+}
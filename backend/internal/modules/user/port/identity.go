@@ -0,0 +1,11 @@
+package port
+
+import (
+	"backend/internal/modules/user/domain"
+	"context"
+)
+
+type IdentityRepository interface {
+	Create(ctx context.Context, identity *domain.Identity) error
+	FindBySubject(ctx context.Context, provider, subject string) (*domain.Identity, error)
+}
@@ -0,0 +1,17 @@
+package port
+
+import (
+	"backend/internal/modules/user/domain"
+	"context"
+)
+
+type InviteRepository interface {
+	Create(ctx context.Context, invite *domain.Invite) error
+	FindAll(ctx context.Context) ([]domain.Invite, error)
+	FindByHash(ctx context.Context, hash string) (*domain.Invite, error)
+	// Claim marks an unused invite as used, returning false if it was already taken.
+	Claim(ctx context.Context, id uint) (bool, error)
+	Release(ctx context.Context, id uint) error
+	SetUsedBy(ctx context.Context, id uint, userID uint) error
+	Delete(ctx context.Context, id uint) error
+}
@@ -0,0 +1,10 @@
+package port
+
+import (
+	"backend/internal/modules/user/domain"
+	"context"
+)
+
+type LoginAttemptRepository interface {
+	Create(ctx context.Context, attempt *domain.LoginAttempt) error
+}
@@ -0,0 +1,16 @@
+package port
+
+import (
+	"backend/internal/modules/user/domain"
+	"context"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *domain.RefreshToken) error
+	FindByHash(ctx context.Context, hash string) (*domain.RefreshToken, error)
+	// Rotate revokes the old token and stores its replacement atomically. It returns
+	// false if the old token was already revoked by a concurrent request.
+	Rotate(ctx context.Context, old *domain.RefreshToken, replacement *domain.RefreshToken) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeAllForUser(ctx context.Context, userID uint) error
+}
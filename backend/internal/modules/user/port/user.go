@@ -1,14 +1,29 @@
 package port
 
 import (
+	"backend/internal/core/listing"
 	"backend/internal/modules/user/domain"
 	"context"
 )
 
 type UserRepository interface {
 	Create(ctx context.Context, user *domain.User) error
+	// FindAll returns a page of users and the total number of matches.
+	FindAll(ctx context.Context, params listing.Params) ([]domain.User, int64, error)
+	FindByID(ctx context.Context, id uint) (*domain.User, error)
 	FindByEmail(ctx context.Context, email string) (*domain.User, error)
 	FindByUsername(ctx context.Context, username string) (*domain.User, error)
 	FindByEmailOrUsername(ctx context.Context, email, username string) (*domain.User, error)
+	Update(ctx context.Context, user *domain.User) error
+	Delete(ctx context.Context, id uint) error
+	// ClaimTOTPStep stores step as the user's last accepted TOTP step, returning false if
+	// it isn't later than the stored one, i.e. the code has already been used.
+	ClaimTOTPStep(ctx context.Context, id uint, step int64) (bool, error)
+	// ConsumeRecoveryCode removes a recovery code hash, returning false if it was already used.
+	ConsumeRecoveryCode(ctx context.Context, id uint, hash string) (bool, error)
+	CountActiveOwners(ctx context.Context) (int64, error)
 	Count(ctx context.Context) (int64, error)
+	// EnsureOwner promotes the oldest account to owner when no owner exists, for installs
+	// created before roles were introduced.
+	EnsureOwner(ctx context.Context) error
 }
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"backend/internal/core/listing"
+	"backend/internal/core/rbac"
+	"backend/internal/modules/user/domain"
+	"backend/internal/modules/user/port"
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryUserRepository keeps users in a map, for tests and tools that run without a
+// database. Like the Postgres repository it hands out copies, never the stored values.
+type MemoryUserRepository struct {
+	mu     sync.RWMutex
+	users  map[uint]domain.User
+	nextID uint
+}
+
+var _ port.UserRepository = (*MemoryUserRepository)(nil)
+
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{users: make(map[uint]domain.User)}
+}
+
+func (r *MemoryUserRepository) Create(ctx context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.taken(user, 0) {
+		return gorm.ErrDuplicatedKey
+	}
+
+	r.nextID++
+	now := time.Now()
+	user.ID = r.nextID
+	user.CreatedAt, user.UpdatedAt = now, now
+	// Zero values are replaced with the column defaults, as gorm does
+	if user.Role == "" {
+		user.Role = rbac.RoleViewer
+	}
+	user.IsActive = true
+	r.users[user.ID] = cloneUser(*user)
+	return nil
+}
+
+func (r *MemoryUserRepository) FindAll(ctx context.Context, params listing.Params) ([]domain.User, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	page, total := listing.Slice(r.sorted(), params)
+	return page, total, nil
+}
+
+func (r *MemoryUserRepository) FindByID(ctx context.Context, id uint) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	user = cloneUser(user)
+	return &user, nil
+}
+
+func (r *MemoryUserRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.find(func(u domain.User) bool { return u.Email == email })
+}
+
+func (r *MemoryUserRepository) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
+	return r.find(func(u domain.User) bool { return u.Username == username })
+}
+
+func (r *MemoryUserRepository) FindByEmailOrUsername(ctx context.Context, email, username string) (*domain.User, error) {
+	return r.find(func(u domain.User) bool { return u.Email == email || u.Username == username })
+}
+
+func (r *MemoryUserRepository) Update(ctx context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[user.ID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	if r.taken(user, user.ID) {
+		return gorm.ErrDuplicatedKey
+	}
+
+	user.UpdatedAt = time.Now()
+	r.users[user.ID] = cloneUser(*user)
+	return nil
+}
+
+func (r *MemoryUserRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.users, id)
+	return nil
+}
+
+func (r *MemoryUserRepository) ClaimTOTPStep(ctx context.Context, id uint, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok || user.TOTPLastStep >= step {
+		return false, nil
+	}
+	user.TOTPLastStep = step
+	user.UpdatedAt = time.Now()
+	r.users[id] = user
+	return true, nil
+}
+
+func (r *MemoryUserRepository) ConsumeRecoveryCode(ctx context.Context, id uint, hash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return false, nil
+	}
+	idx := slices.Index(user.RecoveryCodes, hash)
+	if idx < 0 {
+		return false, nil
+	}
+	user.RecoveryCodes = slices.Delete(slices.Clone(user.RecoveryCodes), idx, idx+1)
+	user.UpdatedAt = time.Now()
+	r.users[id] = user
+	return true, nil
+}
+
+func (r *MemoryUserRepository) CountActiveOwners(ctx context.Context) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var count int64
+	for _, user := range r.users {
+		if user.Role == rbac.RoleOwner && user.IsActive {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *MemoryUserRepository) Count(ctx context.Context) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return int64(len(r.users)), nil
+}
+
+func (r *MemoryUserRepository) EnsureOwner(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	users := r.sorted()
+	if len(users) == 0 || slices.ContainsFunc(users, func(u domain.User) bool { return u.Role == rbac.RoleOwner }) {
+		return nil
+	}
+
+	first := r.users[users[0].ID]
+	first.Role = rbac.RoleOwner
+	r.users[first.ID] = first
+	return nil
+}
+
+func (r *MemoryUserRepository) find(match func(domain.User) bool) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, user := range r.sorted() {
+		if match(user) {
+			return &user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// sorted returns copies of all users by ID, the caller must hold the lock.
+func (r *MemoryUserRepository) sorted() []domain.User {
+	users := make([]domain.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, cloneUser(user))
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users
+}
+
+func (r *MemoryUserRepository) taken(user *domain.User, exceptID uint) bool {
+	for id, other := range r.users {
+		if id != exceptID && (other.Username == user.Username || other.Email == user.Email) {
+			return true
+		}
+	}
+	return false
+}
+
+func cloneUser(user domain.User) domain.User {
+	user.RecoveryCodes = slices.Clone(user.RecoveryCodes)
+	return user
+}
+
+// Snapshot implements db.Snapshotter, so a MemoryTransactor can undo writes made in a
+// transaction that fails.
+func (r *MemoryUserRepository) Snapshot() func() {
+	r.mu.RLock()
+	users, nextID := maps.Clone(r.users), r.nextID
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		r.users, r.nextID = users, nextID
+		r.mu.Unlock()
+	}
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"backend/internal/modules/user/domain"
+	"backend/internal/modules/user/port"
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MemoryAPITokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[uint]domain.APIToken
+	nextID uint
+}
+
+var _ port.APITokenRepository = (*MemoryAPITokenRepository)(nil)
+
+func NewMemoryAPITokenRepository() *MemoryAPITokenRepository {
+	return &MemoryAPITokenRepository{tokens: make(map[uint]domain.APIToken)}
+}
+
+func (r *MemoryAPITokenRepository) Create(ctx context.Context, token *domain.APIToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, other := range r.tokens {
+		if other.TokenHash == token.TokenHash {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	r.nextID++
+	token.ID = r.nextID
+	token.CreatedAt = time.Now()
+	r.tokens[token.ID] = cloneAPIToken(*token)
+	return nil
+}
+
+func (r *MemoryAPITokenRepository) FindByUser(ctx context.Context, userID uint) ([]domain.APIToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var tokens []domain.APIToken
+	for _, token := range r.tokens {
+		if token.UserID == userID {
+			tokens = append(tokens, cloneAPIToken(token))
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].ID > tokens[j].ID })
+	return tokens, nil
+}
+
+func (r *MemoryAPITokenRepository) FindByHash(ctx context.Context, hash string) (*domain.APIToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			token = cloneAPIToken(token)
+			return &token, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryAPITokenRepository) TouchLastUsed(ctx context.Context, id uint, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if token, ok := r.tokens[id]; ok {
+		token.LastUsedAt = &at
+		r.tokens[id] = token
+	}
+	return nil
+}
+
+func (r *MemoryAPITokenRepository) Revoke(ctx context.Context, userID, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[id]
+	if !ok || token.UserID != userID || token.RevokedAt != nil {
+		return gorm.ErrRecordNotFound
+	}
+	now := time.Now()
+	token.RevokedAt = &now
+	r.tokens[id] = token
+	return nil
+}
+
+func cloneAPIToken(token domain.APIToken) domain.APIToken {
+	token.Scopes = slices.Clone(token.Scopes)
+	return token
+}
+
+// Snapshot implements db.Snapshotter, so a MemoryTransactor can undo writes made in a
+// transaction that fails.
+func (r *MemoryAPITokenRepository) Snapshot() func() {
+	r.mu.RLock()
+	tokens, nextID := maps.Clone(r.tokens), r.nextID
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		r.tokens, r.nextID = tokens, nextID
+		r.mu.Unlock()
+	}
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"backend/internal/modules/user/domain"
+	"backend/internal/modules/user/port"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MemoryIdentityRepository struct {
+	mu         sync.RWMutex
+	identities []domain.Identity
+}
+
+var _ port.IdentityRepository = (*MemoryIdentityRepository)(nil)
+
+func NewMemoryIdentityRepository() *MemoryIdentityRepository {
+	return &MemoryIdentityRepository{}
+}
+
+func (r *MemoryIdentityRepository) Create(ctx context.Context, identity *domain.Identity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, other := range r.identities {
+		if other.Provider == identity.Provider && other.Subject == identity.Subject {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	identity.ID = uint(len(r.identities) + 1)
+	identity.CreatedAt = time.Now()
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+func (r *MemoryIdentityRepository) FindBySubject(ctx context.Context, provider, subject string) (*domain.Identity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return &identity, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// Snapshot implements db.Snapshotter, so a MemoryTransactor can undo writes made in a
+// transaction that fails.
+func (r *MemoryIdentityRepository) Snapshot() func() {
+	r.mu.RLock()
+	identities := slices.Clone(r.identities)
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		r.identities = identities
+		r.mu.Unlock()
+	}
+}
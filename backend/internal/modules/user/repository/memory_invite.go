@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"backend/internal/core/rbac"
+	"backend/internal/modules/user/domain"
+	"backend/internal/modules/user/port"
+	"context"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MemoryInviteRepository struct {
+	mu      sync.RWMutex
+	invites map[uint]domain.Invite
+	nextID  uint
+}
+
+var _ port.InviteRepository = (*MemoryInviteRepository)(nil)
+
+func NewMemoryInviteRepository() *MemoryInviteRepository {
+	return &MemoryInviteRepository{invites: make(map[uint]domain.Invite)}
+}
+
+func (r *MemoryInviteRepository) Create(ctx context.Context, invite *domain.Invite) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, other := range r.invites {
+		if other.TokenHash == invite.TokenHash {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	r.nextID++
+	invite.ID = r.nextID
+	invite.CreatedAt = time.Now()
+	if invite.Role == "" {
+		invite.Role = rbac.RoleViewer
+	}
+	r.invites[invite.ID] = *invite
+	return nil
+}
+
+func (r *MemoryInviteRepository) FindAll(ctx context.Context) ([]domain.Invite, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	invites := make([]domain.Invite, 0, len(r.invites))
+	for _, invite := range r.invites {
+		invites = append(invites, invite)
+	}
+	sort.Slice(invites, func(i, j int) bool { return invites[i].ID > invites[j].ID })
+	return invites, nil
+}
+
+func (r *MemoryInviteRepository) FindByHash(ctx context.Context, hash string) (*domain.Invite, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, invite := range r.invites {
+		if invite.TokenHash == hash {
+			return &invite, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryInviteRepository) Claim(ctx context.Context, id uint) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	invite, ok := r.invites[id]
+	if !ok || invite.UsedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	invite.UsedAt = &now
+	r.invites[id] = invite
+	return true, nil
+}
+
+func (r *MemoryInviteRepository) Release(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if invite, ok := r.invites[id]; ok {
+		invite.UsedAt = nil
+		r.invites[id] = invite
+	}
+	return nil
+}
+
+func (r *MemoryInviteRepository) SetUsedBy(ctx context.Context, id uint, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if invite, ok := r.invites[id]; ok {
+		invite.UsedByID = &userID
+		r.invites[id] = invite
+	}
+	return nil
+}
+
+func (r *MemoryInviteRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.invites, id)
+	return nil
+}
+
+// Snapshot implements db.Snapshotter, so a MemoryTransactor can undo writes made in a
+// transaction that fails.
+func (r *MemoryInviteRepository) Snapshot() func() {
+	r.mu.RLock()
+	invites, nextID := maps.Clone(r.invites), r.nextID
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		r.invites, r.nextID = invites, nextID
+		r.mu.Unlock()
+	}
+}
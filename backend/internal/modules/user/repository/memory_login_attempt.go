@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"backend/internal/modules/user/domain"
+	"backend/internal/modules/user/port"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+type MemoryLoginAttemptRepository struct {
+	mu       sync.RWMutex
+	attempts []domain.LoginAttempt
+}
+
+var _ port.LoginAttemptRepository = (*MemoryLoginAttemptRepository)(nil)
+
+func NewMemoryLoginAttemptRepository() *MemoryLoginAttemptRepository {
+	return &MemoryLoginAttemptRepository{}
+}
+
+func (r *MemoryLoginAttemptRepository) Create(ctx context.Context, attempt *domain.LoginAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	attempt.ID = uint(len(r.attempts) + 1)
+	attempt.CreatedAt = time.Now()
+	r.attempts = append(r.attempts, *attempt)
+	return nil
+}
+
+// Attempts returns everything recorded so far, oldest first. The port has no reads, so
+// this only exists for tests.
+func (r *MemoryLoginAttemptRepository) Attempts() []domain.LoginAttempt {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.attempts)
+}
+
+// Snapshot implements db.Snapshotter, so a MemoryTransactor can undo writes made in a
+// transaction that fails.
+func (r *MemoryLoginAttemptRepository) Snapshot() func() {
+	r.mu.RLock()
+	attempts := slices.Clone(r.attempts)
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		r.attempts = attempts
+		r.mu.Unlock()
+	}
+}
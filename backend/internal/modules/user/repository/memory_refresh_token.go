@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"backend/internal/modules/user/domain"
+	"backend/internal/modules/user/port"
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MemoryRefreshTokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[uint]domain.RefreshToken
+	nextID uint
+}
+
+var _ port.RefreshTokenRepository = (*MemoryRefreshTokenRepository)(nil)
+
+func NewMemoryRefreshTokenRepository() *MemoryRefreshTokenRepository {
+	return &MemoryRefreshTokenRepository{tokens: make(map[uint]domain.RefreshToken)}
+}
+
+func (r *MemoryRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.create(token)
+}
+
+func (r *MemoryRefreshTokenRepository) FindByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			return &token, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryRefreshTokenRepository) Rotate(ctx context.Context, old *domain.RefreshToken, replacement *domain.RefreshToken) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.tokens[old.ID]
+	if !ok || stored.RevokedAt != nil {
+		return false, nil
+	}
+
+	if err := r.create(replacement); err != nil {
+		return false, err
+	}
+	now := time.Now()
+	stored.RevokedAt = &now
+	stored.ReplacedByID = &replacement.ID
+	r.tokens[stored.ID] = stored
+	return true, nil
+}
+
+func (r *MemoryRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	r.revokeWhere(func(t domain.RefreshToken) bool { return t.FamilyID == familyID })
+	return nil
+}
+
+func (r *MemoryRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uint) error {
+	r.revokeWhere(func(t domain.RefreshToken) bool { return t.UserID == userID })
+	return nil
+}
+
+// create stores token, the caller must hold the lock.
+func (r *MemoryRefreshTokenRepository) create(token *domain.RefreshToken) error {
+	for _, other := range r.tokens {
+		if other.TokenHash == token.TokenHash {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	r.nextID++
+	token.ID = r.nextID
+	token.CreatedAt = time.Now()
+	r.tokens[token.ID] = *token
+	return nil
+}
+
+func (r *MemoryRefreshTokenRepository) revokeWhere(match func(domain.RefreshToken) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for id, token := range r.tokens {
+		if token.RevokedAt == nil && match(token) {
+			token.RevokedAt = &now
+			r.tokens[id] = token
+		}
+	}
+}
+
+// Snapshot implements db.Snapshotter, so a MemoryTransactor can undo writes made in a
+// transaction that fails.
+func (r *MemoryRefreshTokenRepository) Snapshot() func() {
+	r.mu.RLock()
+	tokens, nextID := maps.Clone(r.tokens), r.nextID
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		r.tokens, r.nextID = tokens, nextID
+		r.mu.Unlock()
+	}
+}
@@ -2,9 +2,14 @@ package repository
 
 import (
 	"backend/internal/core/db"
+	"backend/internal/core/listing"
+	"backend/internal/core/rbac"
 	"backend/internal/modules/user/domain"
 	"backend/internal/modules/user/port"
 	"context"
+	"errors"
+
+	"gorm.io/gorm"
 )
 
 type PostgresUserRepository struct{}
@@ -17,12 +22,26 @@ func NewPostgresUserRepository() *PostgresUserRepository {
 }
 
 func (r *PostgresUserRepository) Create(ctx context.Context, user *domain.User) error {
-	return db.DB.WithContext(ctx).Create(user).Error
+	return db.Conn(ctx).Create(user).Error
+}
+
+func (r *PostgresUserRepository) FindAll(ctx context.Context, params listing.Params) ([]domain.User, int64, error) {
+	var users []domain.User
+	total, err := listing.Find(db.Conn(ctx), params, &users)
+	return users, total, err
+}
+
+func (r *PostgresUserRepository) FindByID(ctx context.Context, id uint) (*domain.User, error) {
+	var user domain.User
+	if err := db.Conn(ctx).First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
 }
 
 func (r *PostgresUserRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var user domain.User
-	if err := db.DB.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+	if err := db.Conn(ctx).Where("email = ?", email).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -30,7 +49,7 @@ func (r *PostgresUserRepository) FindByEmail(ctx context.Context, email string)
 
 func (r *PostgresUserRepository) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
 	var user domain.User
-	if err := db.DB.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+	if err := db.Conn(ctx).Where("username = ?", username).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -38,16 +57,73 @@ func (r *PostgresUserRepository) FindByUsername(ctx context.Context, username st
 
 func (r *PostgresUserRepository) FindByEmailOrUsername(ctx context.Context, email, username string) (*domain.User, error) {
 	var user domain.User
-	if err := db.DB.WithContext(ctx).Where("email = ? OR username = ?", email, username).First(&user).Error; err != nil {
+	if err := db.Conn(ctx).Where("email = ? OR username = ?", email, username).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
+func (r *PostgresUserRepository) Update(ctx context.Context, user *domain.User) error {
+	return db.Conn(ctx).Save(user).Error
+}
+
+func (r *PostgresUserRepository) Delete(ctx context.Context, id uint) error {
+	return db.Conn(ctx).Delete(&domain.User{}, id).Error
+}
+
+func (r *PostgresUserRepository) ClaimTOTPStep(ctx context.Context, id uint, step int64) (bool, error) {
+	// Conditional, so of two requests presenting the same code only one can succeed
+	res := db.Conn(ctx).Model(&domain.User{}).
+		Where("id = ? AND totp_last_step < ?", id, step).
+		Update("totp_last_step", step)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *PostgresUserRepository) ConsumeRecoveryCode(ctx context.Context, id uint, hash string) (bool, error) {
+	res := db.Conn(ctx).Model(&domain.User{}).
+		Where("id = ? AND ? = ANY(recovery_codes)", id, hash).
+		Update("recovery_codes", gorm.Expr("array_remove(recovery_codes, ?)", hash))
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *PostgresUserRepository) CountActiveOwners(ctx context.Context) (int64, error) {
+	var count int64
+	err := db.Conn(ctx).Model(&domain.User{}).
+		Where("role = ? AND is_active = ?", rbac.RoleOwner, true).
+		Count(&count).Error
+	return count, err
+}
+
 func (r *PostgresUserRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
-	if err := db.DB.WithContext(ctx).Model(&domain.User{}).Count(&count).Error; err != nil {
+	if err := db.Conn(ctx).Model(&domain.User{}).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
+
+// DropLegacyUniqueIndexes removes the unique indexes on username and email that also
+// covered soft-deleted users, they are replaced by partial indexes on live rows.
+func (r *PostgresUserRepository) DropLegacyUniqueIndexes(ctx context.Context) error {
+	return db.Conn(ctx).Exec("DROP INDEX IF EXISTS idx_users_username, idx_users_email").Error
+}
+
+func (r *PostgresUserRepository) EnsureOwner(ctx context.Context) error {
+	var owners int64
+	if err := db.Conn(ctx).Model(&domain.User{}).Where("role = ?", rbac.RoleOwner).Count(&owners).Error; err != nil {
+		return err
+	}
+	if owners > 0 {
+		return nil
+	}
+
+	var first domain.User
+	err := db.Conn(ctx).Order("id asc").First(&first).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return db.Conn(ctx).Model(&first).Update("role", rbac.RoleOwner).Error
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"backend/internal/core/db"
+	"backend/internal/modules/user/domain"
+	"backend/internal/modules/user/port"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PostgresAPITokenRepository struct{}
+
+var _ port.APITokenRepository = (*PostgresAPITokenRepository)(nil)
+
+func NewPostgresAPITokenRepository() *PostgresAPITokenRepository {
+	return &PostgresAPITokenRepository{}
+}
+
+func (r *PostgresAPITokenRepository) Create(ctx context.Context, token *domain.APIToken) error {
+	return db.Conn(ctx).Create(token).Error
+}
+
+func (r *PostgresAPITokenRepository) FindByUser(ctx context.Context, userID uint) ([]domain.APIToken, error) {
+	var tokens []domain.APIToken
+	if err := db.Conn(ctx).Where("user_id = ?", userID).Order("created_at desc").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *PostgresAPITokenRepository) FindByHash(ctx context.Context, hash string) (*domain.APIToken, error) {
+	var token domain.APIToken
+	if err := db.Conn(ctx).Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *PostgresAPITokenRepository) TouchLastUsed(ctx context.Context, id uint, at time.Time) error {
+	return db.Conn(ctx).Model(&domain.APIToken{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
+
+func (r *PostgresAPITokenRepository) Revoke(ctx context.Context, userID, id uint) error {
+	res := db.Conn(ctx).Model(&domain.APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"backend/internal/core/db"
+	"backend/internal/modules/user/domain"
+	"backend/internal/modules/user/port"
+	"context"
+)
+
+type PostgresIdentityRepository struct{}
+
+var _ port.IdentityRepository = (*PostgresIdentityRepository)(nil)
+
+func NewPostgresIdentityRepository() *PostgresIdentityRepository {
+	return &PostgresIdentityRepository{}
+}
+
+func (r *PostgresIdentityRepository) Create(ctx context.Context, identity *domain.Identity) error {
+	return db.Conn(ctx).Create(identity).Error
+}
+
+func (r *PostgresIdentityRepository) FindBySubject(ctx context.Context, provider, subject string) (*domain.Identity, error) {
+	var identity domain.Identity
+	if err := db.Conn(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"backend/internal/core/db"
+	"backend/internal/modules/user/domain"
+	"backend/internal/modules/user/port"
+	"context"
+	"time"
+)
+
+type PostgresInviteRepository struct{}
+
+var _ port.InviteRepository = (*PostgresInviteRepository)(nil)
+
+func NewPostgresInviteRepository() *PostgresInviteRepository {
+	return &PostgresInviteRepository{}
+}
+
+func (r *PostgresInviteRepository) Create(ctx context.Context, invite *domain.Invite) error {
+	return db.Conn(ctx).Create(invite).Error
+}
+
+func (r *PostgresInviteRepository) FindAll(ctx context.Context) ([]domain.Invite, error) {
+	var invites []domain.Invite
+	if err := db.Conn(ctx).Order("created_at desc").Find(&invites).Error; err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+func (r *PostgresInviteRepository) FindByHash(ctx context.Context, hash string) (*domain.Invite, error) {
+	var invite domain.Invite
+	if err := db.Conn(ctx).Where("token_hash = ?", hash).First(&invite).Error; err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (r *PostgresInviteRepository) Claim(ctx context.Context, id uint) (bool, error) {
+	res := db.Conn(ctx).Model(&domain.Invite{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *PostgresInviteRepository) Release(ctx context.Context, id uint) error {
+	return db.Conn(ctx).Model(&domain.Invite{}).Where("id = ?", id).Update("used_at", nil).Error
+}
+
+func (r *PostgresInviteRepository) SetUsedBy(ctx context.Context, id uint, userID uint) error {
+	return db.Conn(ctx).Model(&domain.Invite{}).Where("id = ?", id).Update("used_by_id", userID).Error
+}
+
+func (r *PostgresInviteRepository) Delete(ctx context.Context, id uint) error {
+	return db.Conn(ctx).Delete(&domain.Invite{}, id).Error
+}
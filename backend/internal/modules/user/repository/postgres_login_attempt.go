@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"backend/internal/core/db"
+	"backend/internal/modules/user/domain"
+	"backend/internal/modules/user/port"
+	"context"
+)
+
+type PostgresLoginAttemptRepository struct{}
+
+var _ port.LoginAttemptRepository = (*PostgresLoginAttemptRepository)(nil)
+
+func NewPostgresLoginAttemptRepository() *PostgresLoginAttemptRepository {
+	return &PostgresLoginAttemptRepository{}
+}
+
+func (r *PostgresLoginAttemptRepository) Create(ctx context.Context, attempt *domain.LoginAttempt) error {
+	return db.Conn(ctx).Create(attempt).Error
+}
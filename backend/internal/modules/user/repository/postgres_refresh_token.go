@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"backend/internal/core/db"
+	"backend/internal/modules/user/domain"
+	"backend/internal/modules/user/port"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PostgresRefreshTokenRepository struct{}
+
+var _ port.RefreshTokenRepository = (*PostgresRefreshTokenRepository)(nil)
+
+func NewPostgresRefreshTokenRepository() *PostgresRefreshTokenRepository {
+	return &PostgresRefreshTokenRepository{}
+}
+
+func (r *PostgresRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	return db.Conn(ctx).Create(token).Error
+}
+
+func (r *PostgresRefreshTokenRepository) FindByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	if err := db.Conn(ctx).Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *PostgresRefreshTokenRepository) Rotate(ctx context.Context, old *domain.RefreshToken, replacement *domain.RefreshToken) (bool, error) {
+	rotated := false
+	err := db.Conn(ctx).Transaction(func(tx *gorm.DB) error {
+		// Conditional update so two concurrent refreshes can't both win
+		res := tx.Model(&domain.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", old.ID).
+			Update("revoked_at", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Create(replacement).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.RefreshToken{}).Where("id = ?", old.ID).Update("replaced_by_id", replacement.ID).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
+
+func (r *PostgresRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	return db.Conn(ctx).Model(&domain.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *PostgresRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uint) error {
+	return db.Conn(ctx).Model(&domain.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
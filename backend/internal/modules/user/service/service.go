@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"backend/internal/core/audit"
+	"backend/internal/core/db"
+	"backend/internal/core/listing"
+	"backend/internal/core/rbac"
+	"backend/internal/core/validation"
+	authService "backend/internal/modules/auth/service"
+	"backend/internal/modules/user/domain"
+	"backend/internal/modules/user/port"
+
+	"gorm.io/gorm"
+)
+
+// Arbitrary key of the advisory lock serializing changes that could remove the last owner
+const ownersLockKey int64 = 0x6f776e657273
+
+var (
+	ErrLastOwner   = errors.New("cannot remove, demote or deactivate the last active owner")
+	ErrUserExists  = errors.New("username or email already exists")
+	ErrInvalidRole = errors.New("invalid role")
+)
+
+type UserService struct {
+	userRepo    port.UserRepository
+	refreshRepo port.RefreshTokenRepository
+	authSvc     *authService.AuthService
+	tx          db.Transactor
+}
+
+func NewUserService(
+	userRepo port.UserRepository,
+	refreshRepo port.RefreshTokenRepository,
+	authSvc *authService.AuthService,
+	tx db.Transactor,
+) *UserService {
+	return &UserService{
+		userRepo:    userRepo,
+		refreshRepo: refreshRepo,
+		authSvc:     authSvc,
+		tx:          tx,
+	}
+}
+
+type UserInput struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+func (s *UserService) GetAllUsers(ctx context.Context, params listing.Params) ([]domain.User, int64, error) {
+	return s.userRepo.FindAll(ctx, params)
+}
+
+func (s *UserService) GetUser(ctx context.Context, id uint) (*domain.User, error) {
+	return s.userRepo.FindByID(ctx, id)
+}
+
+func (s *UserService) CreateUser(ctx context.Context, input UserInput) (*domain.User, error) {
+	if input.Role == "" {
+		input.Role = rbac.RoleViewer
+	}
+	user, err := s.authSvc.CreateUser(ctx, input.Username, input.Email, input.Password, input.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	recordUserChange(ctx, "user.create", nil, user)
+	return user, nil
+}
+
+// UpdateUser lets an admin change a user's profile and role.
+func (s *UserService) UpdateUser(ctx context.Context, id uint, input UserInput) (*domain.User, error) {
+	var user *domain.User
+	var before domain.User
+	err := s.withOwnersLocked(ctx, func(ctx context.Context) error {
+		var err error
+		if user, err = s.userRepo.FindByID(ctx, id); err != nil {
+			return err
+		}
+		before = *user
+
+		if input.Role != "" && input.Role != user.Role {
+			if !rbac.IsValidRole(input.Role) {
+				return ErrInvalidRole
+			}
+			if err := s.ensureNotLastOwner(ctx, user); err != nil {
+				return err
+			}
+			user.Role = input.Role
+		}
+
+		if err := s.applyProfile(ctx, user, input.Username, input.Email); err != nil {
+			return err
+		}
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrUserExists
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordUserChange(ctx, "user.update", &before, user)
+	return user, nil
+}
+
+// SetActive deactivates or reactivates a user. Deactivation also ends their sessions.
+func (s *UserService) SetActive(ctx context.Context, id uint, active bool) (*domain.User, error) {
+	var user *domain.User
+	var before domain.User
+	changed := false
+	err := s.withOwnersLocked(ctx, func(ctx context.Context) error {
+		var err error
+		if user, err = s.userRepo.FindByID(ctx, id); err != nil {
+			return err
+		}
+		if user.IsActive == active {
+			return nil
+		}
+		before = *user
+
+		if !active {
+			if err := s.ensureNotLastOwner(ctx, user); err != nil {
+				return err
+			}
+		}
+
+		user.IsActive = active
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		if !active {
+			if err := s.refreshRepo.RevokeAllForUser(ctx, user.ID); err != nil {
+				return err
+			}
+		}
+		changed = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return user, nil
+	}
+
+	action := "user.deactivate"
+	if active {
+		action = "user.activate"
+	}
+	recordUserChange(ctx, action, &before, user)
+	return user, nil
+}
+
+func (s *UserService) DeleteUser(ctx context.Context, id uint) error {
+	var user *domain.User
+	err := s.withOwnersLocked(ctx, func(ctx context.Context) error {
+		var err error
+		if user, err = s.userRepo.FindByID(ctx, id); err != nil {
+			return err
+		}
+		if err := s.ensureNotLastOwner(ctx, user); err != nil {
+			return err
+		}
+
+		if err := s.refreshRepo.RevokeAllForUser(ctx, user.ID); err != nil {
+			return err
+		}
+		return s.userRepo.Delete(ctx, user.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	recordUserChange(ctx, "user.delete", user, nil)
+	return nil
+}
+
+// UpdateProfile is the self-service edit, it can't change role or status.
+func (s *UserService) UpdateProfile(ctx context.Context, id uint, username, email string) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *user
+
+	if err := s.applyProfile(ctx, user, username, email); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrUserExists
+		}
+		return nil, err
+	}
+
+	recordUserChange(ctx, "user.profile_update", &before, user)
+	return user, nil
+}
+
+// recordUserChange audits a change to an existing user, after is nil for deletions.
+func recordUserChange(ctx context.Context, action string, before, after *domain.User) {
+	target := before
+	if target == nil {
+		target = after
+	}
+	audit.Record(ctx, audit.Event{
+		Action:     action,
+		TargetType: "user",
+		TargetID:   audit.ID(target.ID),
+		Changes:    audit.Diff(before, after),
+	})
+}
+
+func (s *UserService) applyProfile(ctx context.Context, user *domain.User, username, email string) error {
+	if username == "" {
+		username = user.Username
+	}
+	if email == "" {
+		email = user.Email
+	}
+
+	errs := validation.Errors{}
+	if username != user.Username {
+		errs.Add("username", validation.Username(username))
+	}
+	if email != user.Email {
+		errs.Add("email", validation.Email(email))
+	}
+	if err := errs.Err(); err != nil {
+		return err
+	}
+
+	if existing, err := s.userRepo.FindByEmail(ctx, email); err == nil && existing.ID != user.ID {
+		return ErrUserExists
+	}
+	if existing, err := s.userRepo.FindByUsername(ctx, username); err == nil && existing.ID != user.ID {
+		return ErrUserExists
+	}
+
+	user.Username = username
+	user.Email = email
+	return nil
+}
+
+// withOwnersLocked runs fn in a transaction that holds the owners lock, so that two
+// admins demoting or deactivating each other can't both pass ensureNotLastOwner. fn has
+// to load the users it changes itself, after the lock is taken.
+func (s *UserService) withOwnersLocked(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.tx.Lock(ctx, ownersLockKey); err != nil {
+			return err
+		}
+		return fn(ctx)
+	})
+}
+
+// ensureNotLastOwner fails if user is the only active owner left. It must run under
+// withOwnersLocked.
+func (s *UserService) ensureNotLastOwner(ctx context.Context, user *domain.User) error {
+	if user.Role != rbac.RoleOwner || !user.IsActive {
+		return nil
+	}
+
+	owners, err := s.userRepo.CountActiveOwners(ctx)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"backend/internal/core/db"
+	"backend/internal/core/rbac"
+	"backend/internal/core/validation"
+	"backend/internal/modules/auth/service/authtest"
+	"backend/internal/modules/user/domain"
+	"backend/internal/modules/user/repository"
+)
+
+type userFixture struct {
+	svc   *UserService
+	users *repository.MemoryUserRepository
+}
+
+func newUserFixture(t *testing.T) *userFixture {
+	t.Helper()
+
+	auth := authtest.New(t, nil)
+	tx := db.NewMemoryTransactor(auth.Users, auth.RefreshTokens)
+	return &userFixture{svc: NewUserService(auth.Users, auth.RefreshTokens, auth.Auth, tx), users: auth.Users}
+}
+
+func (f *userFixture) create(t *testing.T, username, role string) *domain.User {
+	t.Helper()
+	user, err := f.svc.CreateUser(context.Background(), UserInput{
+		Username: username,
+		Email:    username + "@example.com",
+		Password: authtest.Password,
+		Role:     role,
+	})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	return user
+}
+
+func TestLastOwnerCannotBeRemoved(t *testing.T) {
+	f := newUserFixture(t)
+	ctx := context.Background()
+	owner := f.create(t, "owner", rbac.RoleOwner)
+
+	if _, err := f.svc.UpdateUser(ctx, owner.ID, UserInput{Role: rbac.RoleEditor}); !errors.Is(err, ErrLastOwner) {
+		t.Fatalf("demote: expected ErrLastOwner, got %v", err)
+	}
+	if _, err := f.svc.SetActive(ctx, owner.ID, false); !errors.Is(err, ErrLastOwner) {
+		t.Fatalf("deactivate: expected ErrLastOwner, got %v", err)
+	}
+	if err := f.svc.DeleteUser(ctx, owner.ID); !errors.Is(err, ErrLastOwner) {
+		t.Fatalf("delete: expected ErrLastOwner, got %v", err)
+	}
+
+	stored, err := f.users.FindByID(ctx, owner.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Role != rbac.RoleOwner || !stored.IsActive {
+		t.Fatalf("expected the owner to be unchanged, got %+v", stored)
+	}
+}
+
+func TestOwnerCanBeRemovedWhileAnotherRemains(t *testing.T) {
+	f := newUserFixture(t)
+	ctx := context.Background()
+	first := f.create(t, "first", rbac.RoleOwner)
+	second := f.create(t, "second", rbac.RoleOwner)
+
+	if _, err := f.svc.UpdateUser(ctx, first.ID, UserInput{Role: rbac.RoleEditor}); err != nil {
+		t.Fatalf("demote: %v", err)
+	}
+	if _, err := f.svc.SetActive(ctx, second.ID, false); !errors.Is(err, ErrLastOwner) {
+		t.Fatalf("expected the remaining owner to be protected, got %v", err)
+	}
+}
+
+func TestCreateUserReportsTakenFields(t *testing.T) {
+	f := newUserFixture(t)
+	f.create(t, "author", rbac.RoleAuthor)
+
+	_, err := f.svc.CreateUser(context.Background(), UserInput{
+		Username: "other",
+		Email:    "author@example.com",
+		Password: authtest.Password,
+	})
+	errs, ok := validation.As(err)
+	if !ok {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+	if errs["email"] == "" || errs["username"] != "" {
+		t.Fatalf("expected only the email to be taken, got %v", errs)
+	}
+}
+
+func TestUpdateUserRejectsTakenEmail(t *testing.T) {
+	f := newUserFixture(t)
+	f.create(t, "first", rbac.RoleAuthor)
+	second := f.create(t, "second", rbac.RoleAuthor)
+
+	_, err := f.svc.UpdateUser(context.Background(), second.ID, UserInput{Email: "first@example.com"})
+	if !errors.Is(err, ErrUserExists) {
+		t.Fatalf("expected ErrUserExists, got %v", err)
+	}
+}